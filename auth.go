@@ -0,0 +1,280 @@
+package gget
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	OAUTH_AUTH_URL  = "https://accounts.google.com/o/oauth2/v2/auth"
+	OAUTH_TOKEN_URL = "https://oauth2.googleapis.com/token"
+	DRIVE_SCOPE     = "https://www.googleapis.com/auth/drive.readonly"
+	// DRIVE_WRITE_SCOPE is needed to upload files
+	DRIVE_WRITE_SCOPE = "https://www.googleapis.com/auth/drive"
+)
+
+// scopeParam joins scopes for a token request, defaulting to read-only
+// access.
+func scopeParam(scopes []string) string {
+	if len(scopes) == 0 {
+		return DRIVE_SCOPE
+	}
+	return strings.Join(scopes, " ")
+}
+
+// TokenSource supplies OAuth2 access tokens for Drive API requests.
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// StaticToken is a TokenSource for an access token obtained elsewhere,
+// such as from `gcloud auth print-access-token`. It is not refreshed.
+type StaticToken string
+
+func (t StaticToken) Token() (string, error) {
+	return string(t), nil
+}
+
+// savedToken is the cached result of `gget auth login`.
+type savedToken struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// refreshTokenSource exchanges a cached refresh token for access tokens,
+// reusing each access token until shortly before it expires.
+type refreshTokenSource struct {
+	client *http.Client
+	saved  savedToken
+
+	mu          sync.Mutex
+	accessToken string
+	expiry      time.Time
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	Error        string `json:"error"`
+	Description  string `json:"error_description"`
+}
+
+func tokenPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate config directory: %v", err)
+	}
+	return filepath.Join(dir, "gget", "token.json"), nil
+}
+
+// UseSavedLogin authenticates g with the login cached by Login, if any.
+func (g *Client) UseSavedLogin() error {
+	source, err := loadTokenSource(g.client)
+	if err != nil {
+		return err
+	}
+	if source != nil {
+		g.Auth = source
+	}
+	return nil
+}
+
+// loadTokenSource returns a token source for the cached login, or nil if
+// the user has not logged in.
+func loadTokenSource(client *http.Client) (*refreshTokenSource, error) {
+	data, source, err := loadToken()
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var saved savedToken
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("invalid cached token in %s: %v", source, err)
+	}
+	return &refreshTokenSource{client: client, saved: saved}, nil
+}
+
+func (s *refreshTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && time.Now().Before(s.expiry) {
+		return s.accessToken, nil
+	}
+
+	token, err := requestToken(s.client, OAUTH_TOKEN_URL, url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {s.saved.ClientID},
+		"client_secret": {s.saved.ClientSecret},
+		"refresh_token": {s.saved.RefreshToken},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh access token (try `gget auth login`): %v", err)
+	}
+
+	s.accessToken = token.AccessToken
+	s.expiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return s.accessToken, nil
+}
+
+// requestToken posts form to an OAuth2 token endpoint.
+func requestToken(client *http.Client, tokenURL string, form url.Values) (*tokenResponse, error) {
+	resp, err := client.PostForm(tokenURL, form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("invalid token response: %v", err)
+	}
+	if token.Error != "" {
+		return nil, fmt.Errorf("%s: %s", token.Error, token.Description)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return nil, fmt.Errorf("token request failed: %s", resp.Status)
+	}
+	return &token, nil
+}
+
+// Login runs the OAuth2 authorization code flow with PKCE for a desktop
+// OAuth client, receiving the code on a local callback server, and caches
+// the refresh token for later runs where TokenStorage says. It asks for
+// scopes, or for read-only access to Drive if none are given. The code is
+// exchanged for a token through g's transport.
+func (g *Client) Login(clientID, clientSecret string, scopes ...string) error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to start callback server: %v", err)
+	}
+	defer listener.Close()
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr())
+
+	state := randomString()
+	verifier := randomString()
+	challenge := sha256.Sum256([]byte(verifier))
+
+	authURL := OAUTH_AUTH_URL + "?" + url.Values{
+		"client_id":             {clientID},
+		"redirect_uri":          {redirectURI},
+		"response_type":         {"code"},
+		"scope":                 {scopeParam(scopes)},
+		"access_type":           {"offline"},
+		"prompt":                {"consent"},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+
+	codes := make(chan string, 1)
+	errs := make(chan error, 1)
+	// Only the first callback counts; later ones, such as a reload of the
+	// page, must not block the handler
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Get("state") != state:
+			http.Error(w, "Invalid state", http.StatusBadRequest)
+			select {
+			case errs <- fmt.Errorf("callback state mismatch"):
+			default:
+			}
+		case query.Get("error") != "":
+			http.Error(w, "Authorization failed", http.StatusBadRequest)
+			select {
+			case errs <- fmt.Errorf("authorization failed: %s", query.Get("error")):
+			default:
+			}
+		default:
+			fmt.Fprintln(w, "gget is authorized. You can close this window.")
+			select {
+			case codes <- query.Get("code"):
+			default:
+			}
+		}
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Printf("Opening your browser to authorize gget. If it does not open, visit:\n\n  %s\n\n", authURL)
+	openBrowser(authURL)
+
+	var code string
+	select {
+	case code = <-codes:
+	case err := <-errs:
+		return err
+	case <-time.After(5 * time.Minute):
+		return fmt.Errorf("timed out waiting for authorization")
+	}
+
+	token, err := requestToken(g.client, OAUTH_TOKEN_URL, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to exchange authorization code: %v", err)
+	}
+	if token.RefreshToken == "" {
+		return fmt.Errorf("no refresh token was returned")
+	}
+
+	data, _ := json.MarshalIndent(savedToken{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RefreshToken: token.RefreshToken,
+	}, "", "  ")
+	where, err := saveToken(data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Logged in; token saved to %s\n", where)
+	return nil
+}
+
+// Logout removes the cached login.
+func Logout() error {
+	return deleteToken()
+}
+
+func randomString() string {
+	buf := make([]byte, 32)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func openBrowser(target string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", target)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	cmd.Start()
+}
@@ -0,0 +1,93 @@
+package gget
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumAlgorithm names the hash written to checksum sidecar files.
+type ChecksumAlgorithm string
+
+const (
+	NO_CHECKSUM ChecksumAlgorithm = ""
+	MD5         ChecksumAlgorithm = "md5"
+	SHA1        ChecksumAlgorithm = "sha1"
+	SHA256      ChecksumAlgorithm = "sha256"
+	SHA512      ChecksumAlgorithm = "sha512"
+)
+
+var checksumHashes = map[ChecksumAlgorithm]func() hash.Hash{
+	MD5:    md5.New,
+	SHA1:   sha1.New,
+	SHA256: sha256.New,
+	SHA512: sha512.New,
+}
+
+// ParseChecksumAlgorithm parses a -write-checksum algorithm: md5, sha1,
+// sha256 or sha512.
+func ParseChecksumAlgorithm(name string) (ChecksumAlgorithm, error) {
+	algorithm := ChecksumAlgorithm(strings.ToLower(name))
+	if _, ok := checksumHashes[algorithm]; !ok && algorithm != NO_CHECKSUM {
+		return NO_CHECKSUM, fmt.Errorf("unknown checksum algorithm %q (use md5, sha1, sha256 or sha512)", name)
+	}
+	return algorithm, nil
+}
+
+// sidecarHash starts the ChecksumFile hash of a .part file that already
+// holds offset bytes, reading those back so that the rest can be hashed
+// as it is written. It returns nil when no sidecar is wanted.
+func (g *Client) sidecarHash(part string, offset int64) (hash.Hash, error) {
+	newHash := checksumHashes[g.ChecksumFile]
+	if newHash == nil {
+		return nil, nil
+	}
+	h := newHash()
+	if offset == 0 {
+		return h, nil
+	}
+
+	file, err := os.Open(part)
+	if err != nil {
+		return nil, classify(ErrDisk, fmt.Errorf("failed to read partial file: %v", err))
+	}
+	defer file.Close()
+	if _, err := io.CopyN(h, file, offset); err != nil {
+		return nil, classify(ErrDisk, fmt.Errorf("failed to read partial file: %v", err))
+	}
+	return h, nil
+}
+
+// writeChecksumFile writes output.<algorithm> in the format of sha256sum
+// and friends. h is the hash fed while downloading; without one, as for
+// segmented downloads, the finished file is read back.
+func (g *Client) writeChecksumFile(output string, h hash.Hash) error {
+	if h == nil {
+		var err error
+		if h, err = g.sidecarHash(output, 0); h == nil || err != nil {
+			return err
+		}
+		file, err := os.Open(output)
+		if err != nil {
+			return classify(ErrDisk, fmt.Errorf("failed to read downloaded file: %v", err))
+		}
+		defer file.Close()
+		if _, err := io.Copy(h, file); err != nil {
+			return classify(ErrDisk, fmt.Errorf("failed to read downloaded file: %v", err))
+		}
+	}
+
+	line := fmt.Sprintf("%s  %s\n", hex.EncodeToString(h.Sum(nil)), filepath.Base(output))
+	if err := os.WriteFile(output+"."+string(g.ChecksumFile), []byte(line), 0666); err != nil {
+		return classify(ErrDisk, fmt.Errorf("failed to write checksum file: %v", err))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package gget
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ClobberMode says what happens when a download's output file already
+// exists.
+type ClobberMode int
+
+const (
+	OVERWRITE   ClobberMode = iota // replace the existing file
+	NO_CLOBBER                     // keep the existing file and skip the download
+	AUTO_RENAME                    // save as "name (1).ext", "name (2).ext", ...
+)
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// availableName returns the first "name (n).ext" variant of output that
+// does not exist yet.
+func availableName(output string) string {
+	ext := filepath.Ext(output)
+	base := strings.TrimSuffix(output, ext)
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, n, ext)
+		if !exists(longPath(candidate)) {
+			return candidate
+		}
+	}
+}
+
+// notOlder reports whether t.output exists with the remote size (when
+// known) and a modification time no older than the remote file's, in which
+// case -timestamps skips the download like wget -N.
+func notOlder(t *transfer, size int64) bool {
+	if t.modified.IsZero() {
+		return false
+	}
+	info, err := os.Stat(t.path())
+	if err != nil || (size >= 0 && info.Size() != size) {
+		return false
+	}
+	return !info.ModTime().Before(t.modified.Truncate(time.Second))
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/phx/gget"
+)
+
+const DEFAULT_CHECKPOINT_FILE = ".gget-progress"
+
+// batchEntry is a single download: a URL or file ID and an optional
+// output name.
+type batchEntry struct {
+	URL    string
+	Output string
+	Index  int // position in the batch, from 1
+}
+
+// key identifies the entry in a checkpoint file.
+func (e batchEntry) key() string {
+	if e.Output == "" {
+		return e.URL
+	}
+	return e.URL + " " + e.Output
+}
+
+// doneLog records which entries have completed: a checkpoint file or
+// the job queue.
+type doneLog interface {
+	isDone(entry string) bool
+	markDone(entry string) error
+}
+
+type batchResult struct {
+	succeeded   int
+	skipped     int
+	failed      []string
+	firstErr    error
+	interrupted bool
+}
+
+// readBatchFile reads one URL or file ID per line, optionally followed by
+// spaces or tabs and an output name. Blank lines and lines starting with # are ignored.
+func readBatchFile(path string) ([]batchEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input file: %v", err)
+	}
+	defer file.Close()
+
+	var entries []batchEntry
+	err = scanBatch(file, func(entry batchEntry) bool {
+		entries = append(entries, entry)
+		return true
+	})
+	return entries, err
+}
+
+// scanBatch parses lines in the format of readBatchFile from r, passing
+// each entry to yield as soon as its line has been read.
+func scanBatch(r io.Reader, yield func(batchEntry) bool) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entry := batchEntry{URL: line}
+		if i := strings.IndexFunc(line, unicode.IsSpace); i >= 0 {
+			entry.URL, entry.Output = line[:i], strings.TrimSpace(line[i:])
+		}
+		if !yield(entry) {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read input file: %v", err)
+	}
+	return nil
+}
+
+// stdinEntries streams entries from standard input, so that downloads
+// start while a pipeline is still producing URLs.
+func stdinEntries() iter.Seq[batchEntry] {
+	return func(yield func(batchEntry) bool) {
+		if err := scanBatch(os.Stdin, yield); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	}
+}
+
+// stdinIsPiped reports whether standard input is a pipe or file rather
+// than a terminal or /dev/null.
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// runBatch downloads the entries with up to jobs concurrent workers,
+// continuing past failures and skipping entries already recorded in any
+// of logs. Failures are printed as they happen unless showErrors is
+// false, as with -tui, which lists them when it stops.
+func runBatch(entries iter.Seq[batchEntry], download func(batchEntry) error, logs []doneLog, quiet bool, showErrors bool, jobs int) batchResult {
+	var (
+		result batchResult
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+	)
+
+	queue := make(chan batchEntry)
+	for i := 0; i < max(jobs, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range queue {
+				err := download(entry)
+
+				mu.Lock()
+				if err != nil {
+					if showErrors {
+						fmt.Fprintf(os.Stderr, "Error: %s: %v\n", entry.URL, err)
+					}
+					result.failed = append(result.failed, entry.URL)
+					if result.firstErr == nil {
+						result.firstErr = err
+					}
+					if errors.Is(err, context.Canceled) {
+						result.interrupted = true
+					}
+				} else {
+					result.succeeded++
+					for _, log := range logs {
+						if err := log.markDone(entry.key()); err != nil {
+							fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+						}
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	index := 0
+	for entry := range entries {
+		index++
+		entry.Index = index
+		// The workers update logs under mu as entries finish
+		mu.Lock()
+		interrupted, done := result.interrupted, isDone(logs, entry.key())
+		if done && !interrupted {
+			result.skipped++
+		}
+		mu.Unlock()
+		if interrupted {
+			break
+		}
+
+		if done {
+			if !quiet {
+				fmt.Printf("Skipping %s (already completed)\n", entry.URL)
+			}
+			continue
+		}
+		queue <- entry
+	}
+	close(queue)
+	wg.Wait()
+
+	return result
+}
+
+func isDone(logs []doneLog, entry string) bool {
+	for _, log := range logs {
+		if log.isDone(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r batchResult) printSummary() {
+	fmt.Printf("\nDownloaded %d, skipped %d, failed %d\n", r.succeeded, r.skipped, len(r.failed))
+	for _, url := range r.failed {
+		fmt.Printf("  failed: %s\n", url)
+	}
+}
+
+// printStats writes the closing report of a run that took elapsed, like
+// wget's: bytes transferred, wall time, average and peak speed, retries
+// and skipped files.
+func printStats(w io.Writer, stats gget.Stats, elapsed time.Duration) {
+	var speed int64
+	if elapsed > 0 {
+		speed = int64(float64(stats.Bytes) / elapsed.Seconds())
+	}
+	fmt.Fprintf(w, "Transferred %s in %v (%s/s, peak %s/s); files: %d done, %d skipped; retries: %d\n",
+		gget.FormatSize(stats.Bytes), elapsed.Round(10*time.Millisecond), gget.FormatSize(speed), gget.FormatSize(stats.PeakSpeed),
+		stats.Files, stats.Skipped, stats.Retries)
+}
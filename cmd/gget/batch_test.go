@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunBatchResumedCheckpoint(t *testing.T) {
+	var entries []batchEntry
+	var done []string
+	for i := 0; i < 200; i++ {
+		entry := batchEntry{URL: fmt.Sprintf("https://example.com/%d", i)}
+		entries = append(entries, entry)
+		if i%2 == 0 {
+			done = append(done, entry.key())
+		}
+	}
+	path := filepath.Join(t.TempDir(), "progress")
+	if err := os.WriteFile(path, []byte(strings.Join(done, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	progress, err := loadCheckpoint(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var downloaded atomic.Int64
+	download := func(batchEntry) error {
+		downloaded.Add(1)
+		return nil
+	}
+	result := runBatch(slices.Values(entries), download, []doneLog{progress}, true, true, 8)
+	if result.succeeded != 100 || result.skipped != 100 || downloaded.Load() != 100 {
+		t.Errorf("succeeded %d, skipped %d, downloaded %d; want 100 of each", result.succeeded, result.skipped, downloaded.Load())
+	}
+
+	progress, err = loadCheckpoint(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if !progress.isDone(entry.key()) {
+			t.Errorf("%s is not in the checkpoint", entry.URL)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// checkpoint records which entries of a run have completed so that a
+// restarted run can skip them without re-checking any remote metadata.
+type checkpoint struct {
+	path string
+	done map[string]bool
+}
+
+// loadCheckpoint reads the completed entries from path. With restart set,
+// any existing checkpoint is discarded instead.
+func loadCheckpoint(path string, restart bool) (*checkpoint, error) {
+	c := &checkpoint{path: path, done: make(map[string]bool)}
+
+	if restart {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to reset checkpoint: %v", err)
+		}
+		return c, nil
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if entry := strings.TrimSpace(scanner.Text()); entry != "" {
+			c.done[entry] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+
+	return c, nil
+}
+
+func (c *checkpoint) isDone(entry string) bool {
+	return c.done[entry]
+}
+
+// markDone appends entry to the checkpoint file.
+func (c *checkpoint) markDone(entry string) error {
+	file, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintln(file, entry); err != nil {
+		return fmt.Errorf("failed to update checkpoint: %v", err)
+	}
+	c.done[entry] = true
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommands are the programs tried, in order, to read the
+// clipboard on each system.
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbpaste"}},
+	"windows": {{"powershell", "-NoProfile", "-Command", "Get-Clipboard"}},
+	"linux": {
+		{"wl-paste", "--no-newline"},
+		{"xclip", "-selection", "clipboard", "-o"},
+		{"xsel", "--clipboard", "--output"},
+	},
+}
+
+// readClipboard returns the URLs or file IDs in the system clipboard, one
+// per whitespace-separated word.
+func readClipboard() ([]string, error) {
+	candidates, ok := clipboardCommands[runtime.GOOS]
+	if !ok {
+		candidates = clipboardCommands["linux"]
+	}
+
+	var tried []string
+	for _, argv := range candidates {
+		path, err := exec.LookPath(argv[0])
+		if err != nil {
+			tried = append(tried, argv[0])
+			continue
+		}
+		out, err := exec.Command(path, argv[1:]...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the clipboard with %s: %v", argv[0], err)
+		}
+		urls := strings.Fields(string(out))
+		if len(urls) == 0 {
+			return nil, fmt.Errorf("the clipboard is empty")
+		}
+		return urls, nil
+	}
+	return nil, fmt.Errorf("no clipboard tool found (install one of: %s)", strings.Join(tried, ", "))
+}
+
+// dropFlag removes the boolean flag name from args, so that a job read
+// from the clipboard is resumed with its URLs rather than whatever the
+// clipboard holds by then.
+func dropFlag(args []string, name string) []string {
+	var kept []string
+	for i, arg := range args {
+		if arg == "--" {
+			return append(kept, args[i:]...)
+		}
+		flagName, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if strings.HasPrefix(arg, "-") && flagName == name {
+			continue
+		}
+		kept = append(kept, arg)
+	}
+	return kept
+}
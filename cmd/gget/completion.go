@@ -0,0 +1,202 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var subcommands = []string{"get", "auth", "info", "ls", "revisions", "sync", "verify", "put", "push-sync", "search", "resume", "completion", "version", "help"}
+
+// fileFlags and dirFlags take paths, which shells should complete.
+var (
+	fileFlags = map[string]bool{"o": true, "i": true, "cookies": true, "service-account": true, "checkpoint": true, "config": true}
+	dirFlags  = map[string]bool{"P": true, "directory-prefix": true}
+)
+
+// completionFlag is a flag as shown to a shell: single-letter flags as
+// -x, the rest as --name.
+type completionFlag struct {
+	name    string
+	usage   string
+	isBool  bool
+	isFile  bool
+	isDir   bool
+	display string
+}
+
+func completionFlags() []completionFlag {
+	var flags []completionFlag
+	flag.VisitAll(func(f *flag.Flag) {
+		cf := completionFlag{name: f.Name, usage: f.Usage, isFile: fileFlags[f.Name], isDir: dirFlags[f.Name]}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			cf.isBool = true
+		}
+		cf.display = "--" + f.Name
+		if len(f.Name) == 1 {
+			cf.display = "-" + f.Name
+		}
+		flags = append(flags, cf)
+	})
+	sort.Slice(flags, func(i, j int) bool { return flags[i].name < flags[j].name })
+	return flags
+}
+
+// runCompletion implements `gget completion`, printing a completion
+// script for shell.
+func runCompletion(args []string) error {
+	if len(args) != 1 {
+		return usageErrorf("usage: gget completion bash|zsh|fish|powershell")
+	}
+
+	flags := completionFlags()
+	switch args[0] {
+	case "bash":
+		fmt.Print(bashCompletion(flags))
+	case "zsh":
+		fmt.Print(zshCompletion(flags))
+	case "fish":
+		fmt.Print(fishCompletion(flags))
+	case "powershell":
+		fmt.Print(powershellCompletion(flags))
+	default:
+		return usageErrorf("unsupported shell %q (use bash, zsh, fish or powershell)", args[0])
+	}
+	return nil
+}
+
+func bashCompletion(flags []completionFlag) string {
+	var names, files, dirs, values []string
+	for _, f := range flags {
+		names = append(names, f.display)
+		switch {
+		case f.isFile:
+			files = append(files, "-"+f.name, "--"+f.name)
+		case f.isDir:
+			dirs = append(dirs, "-"+f.name, "--"+f.name)
+		case !f.isBool:
+			values = append(values, "-"+f.name, "--"+f.name)
+		}
+	}
+
+	return fmt.Sprintf(`# bash completion for gget
+_gget() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"
+    case "$prev" in
+        %s) COMPREPLY=($(compgen -f -- "$cur")); return ;;
+        %s) COMPREPLY=($(compgen -d -- "$cur")); return ;;
+        %s) COMPREPLY=(); return ;;
+    esac
+    if [[ $COMP_CWORD -eq 1 && $cur != -* ]]; then
+        COMPREPLY=($(compgen -W "%s" -- "$cur"))
+        return
+    fi
+    case "${COMP_WORDS[1]}" in
+        auth) [[ $COMP_CWORD -eq 2 ]] && COMPREPLY=($(compgen -W "login logout" -- "$cur")); return ;;
+        completion) COMPREPLY=($(compgen -W "bash zsh fish powershell" -- "$cur")); return ;;
+        ls) [[ $cur == -* ]] && COMPREPLY=($(compgen -W "-l -R %s" -- "$cur")); return ;;
+    esac
+    if [[ $cur == -* ]]; then
+        COMPREPLY=($(compgen -W "%s" -- "$cur"))
+    fi
+}
+complete -o default -F _gget gget
+`, strings.Join(files, "|"), strings.Join(dirs, "|"), strings.Join(values, "|"),
+		strings.Join(subcommands, " "), strings.Join(names, " "), strings.Join(names, " "))
+}
+
+// zshDescription escapes a flag usage for an _arguments spec.
+func zshDescription(usage string) string {
+	return strings.NewReplacer(`'`, `'\''`, `[`, `\[`, `]`, `\]`, `:`, `\:`).Replace(usage)
+}
+
+func zshCompletion(flags []completionFlag) string {
+	var b strings.Builder
+	b.WriteString("#compdef gget\n\n_gget() {\n    local -a specs\n    specs=(\n")
+	for _, f := range flags {
+		spec := fmt.Sprintf("'%s[%s]", f.display, zshDescription(f.usage))
+		switch {
+		case f.isFile:
+			spec += ":file:_files"
+		case f.isDir:
+			spec += ":directory:_files -/"
+		case !f.isBool:
+			spec += ":value:"
+		}
+		fmt.Fprintf(&b, "        %s'\n", spec)
+	}
+	fmt.Fprintf(&b, `    )
+    if (( CURRENT == 2 )) && [[ $words[2] != -* ]]; then
+        _values 'command' %s
+        return
+    fi
+    case $words[2] in
+        auth) _values 'auth command' login logout ;;
+        completion) _values 'shell' bash zsh fish powershell ;;
+        ls) _arguments -s '-l[Long listing]' '-R[List recursively]' $specs '*:folder URL:' ;;
+        *) _arguments -s $specs '*:URL:' ;;
+    esac
+}
+
+_gget "$@"
+`, strings.Join(subcommands, " "))
+	return b.String()
+}
+
+func fishCompletion(flags []completionFlag) string {
+	var b strings.Builder
+	b.WriteString("# fish completion for gget\n")
+	fmt.Fprintf(&b, "complete -c gget -n '__fish_use_subcommand' -f -a '%s'\n", strings.Join(subcommands, " "))
+	b.WriteString("complete -c gget -n '__fish_seen_subcommand_from auth' -f -a 'login logout'\n")
+	b.WriteString("complete -c gget -n '__fish_seen_subcommand_from completion' -f -a 'bash zsh fish powershell'\n")
+	b.WriteString("complete -c gget -n '__fish_seen_subcommand_from ls' -s l -d 'Long listing'\n")
+	b.WriteString("complete -c gget -n '__fish_seen_subcommand_from ls' -s R -d 'List recursively'\n")
+	for _, f := range flags {
+		option := "-l " + f.name
+		if len(f.name) == 1 {
+			option = "-s " + f.name
+		}
+		line := fmt.Sprintf("complete -c gget %s -d '%s'", option, strings.ReplaceAll(f.usage, "'", `\'`))
+		switch {
+		case f.isFile:
+			line += " -r -F"
+		case f.isDir:
+			line += " -r -f -a '(__fish_complete_directories)'"
+		case !f.isBool:
+			line += " -r -f"
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+func powershellCompletion(flags []completionFlag) string {
+	var entries []string
+	for _, f := range flags {
+		entries = append(entries, fmt.Sprintf("        @('%s', '%s')", f.display, strings.ReplaceAll(f.usage, "'", "''")))
+	}
+
+	return fmt.Sprintf(`# PowerShell completion for gget
+Register-ArgumentCompleter -Native -CommandName gget -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $words = $commandAst.CommandElements | ForEach-Object { $_.ToString() }
+    if ($words.Count -le 2 -and -not $wordToComplete.StartsWith('-')) {
+        '%s' -split ' ' | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+            [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+        }
+        return
+    }
+    if (-not $wordToComplete.StartsWith('-')) {
+        # Fall back to path completion for -o, -i and URLs
+        return
+    }
+    $flags = @(
+%s
+    )
+    $flags | Where-Object { $_[0] -like "$wordToComplete*" } | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_[0], $_[0], 'ParameterName', $_[1])
+    }
+}
+`, strings.Join(subcommands, " "), strings.Join(entries, "\n"))
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// defaultConfigPath is ~/.config/gget/config.yaml or the platform's
+// equivalent.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "gget", "config.yaml")
+}
+
+// netrcPath picks the .netrc file to read: flagValue when given, else
+// $NETRC or ~/.netrc (~/_netrc on Windows) if it exists, else none.
+func netrcPath(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	path := os.Getenv("NETRC")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		name := ".netrc"
+		if runtime.GOOS == "windows" {
+			name = "_netrc"
+		}
+		path = filepath.Join(home, name)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// loadConfig sets flag values from a config file. The file is a flat YAML
+// mapping of flag names to values, with lists for repeatable flags:
+//
+//	retries: 5
+//	limit-rate: 2M
+//	directory-prefix: ~/Downloads
+//	H:
+//	  - "X-Team: data"
+//
+// It runs after the command line is parsed: flags given there keep their
+// values, and repeatable ones are not added to. A missing file is only an
+// error when it was named explicitly.
+func loadConfig(path string, explicit bool) error {
+	if path == "" {
+		return nil
+	}
+	// Aliases such as -P and -directory-prefix share one Value
+	given := make(map[flag.Value]bool)
+	flag.Visit(func(f *flag.Flag) { given[f.Value] = true })
+	file, err := os.Open(path)
+	if os.IsNotExist(err) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %v", err)
+	}
+	defer file.Close()
+
+	listKey := ""
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if item, ok := strings.CutPrefix(line, "- "); ok {
+			if listKey == "" {
+				return fmt.Errorf("%s:%d: list item outside of a list", path, lineNum)
+			}
+			if err := setConfigFlag(listKey, item, given); err != nil {
+				return fmt.Errorf("%s:%d: %v", path, lineNum, err)
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected \"key: value\"", path, lineNum)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if value == "" {
+			// The values follow as a list
+			listKey = key
+			continue
+		}
+		listKey = ""
+		if err := setConfigFlag(key, value, given); err != nil {
+			return fmt.Errorf("%s:%d: %v", path, lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+	return nil
+}
+
+func setConfigFlag(key string, value string, given map[flag.Value]bool) error {
+	f := flag.Lookup(key)
+	if key == "config" || f == nil {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	if given[f.Value] {
+		return nil
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 && !strings.HasPrefix(value, `"`) && !strings.HasPrefix(value, "'") {
+		value = strings.TrimSpace(value[:i])
+	}
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	if strings.HasPrefix(value, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			value = filepath.Join(home, value[2:])
+		}
+	}
+
+	if err := flag.Set(key, value); err != nil {
+		return fmt.Errorf("invalid value for %s: %v", key, err)
+	}
+	return nil
+}
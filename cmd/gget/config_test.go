@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigAliasGivenOnCommandLine(t *testing.T) {
+	saved := flag.CommandLine
+	t.Cleanup(func() { flag.CommandLine = saved })
+	flag.CommandLine = flag.NewFlagSet("gget", flag.ContinueOnError)
+
+	var prefix string
+	var resume bool
+	var headers headerFlags
+	flag.StringVar(&prefix, "P", "", "")
+	flag.StringVar(&prefix, "directory-prefix", "", "")
+	flag.BoolVar(&resume, "c", false, "")
+	flag.BoolVar(&resume, "continue", false, "")
+	flag.Var(&headers, "H", "")
+	retries := flag.Int("retries", 3, "")
+
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	data := "directory-prefix: /from/config\ncontinue: false\nretries: 7\nH:\n  - \"X-Config: 1\"\n"
+	if err := os.WriteFile(config, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := flag.CommandLine.Parse([]string{"-P", "/from/cli", "-c", "-H", "X-Cli: 1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadConfig(config, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if prefix != "/from/cli" {
+		t.Errorf("prefix = %q, want the -P value", prefix)
+	}
+	if !resume {
+		t.Error("continue from the config overrode -c")
+	}
+	if len(headers) != 1 || headers[0] != "X-Cli: 1" {
+		t.Errorf("headers = %q, want only the -H value", headers)
+	}
+	if *retries != 7 {
+		t.Errorf("retries = %d, want the config value 7", *retries)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/phx/gget"
+)
+
+// promptMu keeps concurrent -j downloads from asking at the same time.
+var promptMu sync.Mutex
+
+// confirmPrompt asks on the terminal whether to go ahead with a download
+// above -confirm-size. The answer is read from the terminal rather than
+// stdin, which may be a list of URLs; without a terminal it is no.
+func confirmPrompt(name string, size int64) bool {
+	promptMu.Lock()
+	defer promptMu.Unlock()
+
+	tty := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		tty = "CONIN$"
+	}
+	in, err := os.Open(tty)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s is %s; pass -yes to download it without a terminal\n", name, gget.FormatSize(size))
+		return false
+	}
+	defer in.Close()
+
+	fmt.Fprintf(os.Stderr, "%s is %s. Download it? [y/N] ", name, gget.FormatSize(size))
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/phx/gget"
+)
+
+// runExec runs the -exec command for a finished file through the shell.
+// {} is replaced by the file's path, {id} by its Drive file ID and {name}
+// by its base name. The values are quoted, so the placeholders must not
+// be quoted in the command.
+func runExec(command string, event gget.Event) error {
+	replacer := strings.NewReplacer(
+		"{}", shellQuote(event.Name),
+		"{id}", shellQuote(event.ID),
+		"{name}", shellQuote(filepath.Base(event.Name)),
+	)
+	script := replacer.Replace(command)
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", script)
+	} else {
+		cmd = exec.Command("sh", "-c", script)
+	}
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("-exec for %s failed: %v", event.Name, err)
+	}
+	return nil
+}
+
+// shellQuote quotes value as a single shell word, so that file names from
+// the remote side cannot inject commands.
+func shellQuote(value string) string {
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
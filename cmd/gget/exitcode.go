@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/phx/gget"
+)
+
+// Exit codes, documented in the README. When several downloads fail the
+// code reflects the first failure.
+const (
+	EXIT_FAILURE     = 1
+	EXIT_USAGE       = 2
+	EXIT_INVALID_URL = 3
+	EXIT_PERMISSION  = 4
+	EXIT_QUOTA       = 5
+	EXIT_NETWORK     = 6
+	EXIT_CHECKSUM    = 7
+	EXIT_DISK        = 8
+)
+
+// exitCode maps an error from the gget package to the exit code for its
+// class.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, context.Canceled):
+		return EXIT_INTERRUPTED
+	case errors.As(err, new(*usageError)):
+		return EXIT_USAGE
+	case errors.Is(err, gget.ErrInvalidURL):
+		return EXIT_INVALID_URL
+	case errors.Is(err, gget.ErrPermissionDenied):
+		return EXIT_PERMISSION
+	case errors.Is(err, gget.ErrQuotaExceeded):
+		return EXIT_QUOTA
+	case errors.Is(err, gget.ErrNetwork):
+		return EXIT_NETWORK
+	case errors.Is(err, gget.ErrChecksumMismatch):
+		return EXIT_CHECKSUM
+	case errors.Is(err, gget.ErrDisk):
+		return EXIT_DISK
+	}
+	return EXIT_FAILURE
+}
+
+// summaryError reports several failures under one message while keeping
+// the first of them for exitCode.
+type summaryError struct {
+	msg   string
+	first error
+}
+
+func (e *summaryError) Error() string {
+	return e.msg
+}
+
+func (e *summaryError) Unwrap() error {
+	return e.first
+}
+
+// usageError is a mistake on the command line rather than a failure.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{fmt.Sprintf(format, args...)}
+}
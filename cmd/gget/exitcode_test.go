@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// TestMain runs gget itself when GGET_TEST_MAIN is set, so that tests can
+// check the exit status of a command line.
+func TestMain(m *testing.M) {
+	if os.Getenv("GGET_TEST_MAIN") != "" {
+		os.Args = append([]string{"gget"}, os.Args[1:]...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestUsageExitCodes(t *testing.T) {
+	tests := [][]string{
+		{"-limit-rate", "foo", "1AbC"},
+		{"-range", "abc", "1AbC"},
+		{"-chmod", "zz", "1AbC"},
+		{"-restrict-filenames", "bogus", "1AbC"},
+		{"-4", "-6", "1AbC"},
+		{"-o", "out.bin", "1AbC", "2DeF"},
+		{"-no-clobber", "-auto-rename", "1AbC"},
+	}
+	for _, args := range tests {
+		cmd := exec.Command(os.Args[0], args...)
+		cmd.Dir = t.TempDir()
+		cmd.Env = append(os.Environ(), "GGET_TEST_MAIN=1", "HOME="+cmd.Dir, "XDG_CONFIG_HOME="+cmd.Dir, "NETRC=")
+		err := cmd.Run()
+		var exit *exec.ExitError
+		if !errors.As(err, &exit) || exit.ExitCode() != EXIT_USAGE {
+			t.Errorf("gget %q: %v, want exit status %d", args, err, EXIT_USAGE)
+		}
+	}
+}
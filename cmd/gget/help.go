@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const VERSION = "1.0.0"
+
+// command describes a subcommand for the usage text and gget help.
+type command struct {
+	name    string
+	usage   string
+	summary string
+}
+
+// commands lists the subcommands in the order the usage text shows them.
+// Every command that works on remote files also takes the download and
+// connection flags of get.
+var commands = []command{
+	{"get", "gget [get] [-o output_filename] [-q] [-id file_id] [-i list.txt] <google_drive_url>...", "Download files and folders; the default command, so get can be left out"},
+	{"info", "gget info <google_drive_url>...", "Show the name, size, type and checksum of files without downloading them"},
+	{"ls", "gget ls [-l] [-R] <folder_url>", "List the items of a folder"},
+	{"revisions", "gget revisions <google_drive_url>", "List the stored revisions of a file"},
+	{"verify", "gget verify <folder_url> <directory>", "Compare a local copy of a folder with the remote one"},
+	{"put", "gget put [-parent folder_url] <file>...", "Upload files to Drive"},
+	{"push-sync", "gget push-sync [-delete] <directory> <folder_url>", "Upload the new and changed files of a directory to a folder"},
+	{"search", "gget search [-download] \"<query>\"", "Find files by name or content"},
+	{"sync", "gget sync <folder_url> [directory]", "Download the new and changed files of a folder"},
+	{"resume", "gget resume [-bearer token] [-basic user:password] [-H header]...", "Continue the interrupted batch or folder download in this directory"},
+	{"auth", "gget auth login|logout", "Sign in to Drive with OAuth2, or forget the saved login"},
+	{"completion", "gget completion bash|zsh|fish|powershell", "Print a shell completion script"},
+	{"version", "gget version", "Show the version"},
+	{"help", "gget help [command]", "Show the commands, or the usage and flags of one"},
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// commandFlags holds the flags only one subcommand takes.
+type commandFlags struct {
+	long, recursive bool   // ls
+	parent          string // put
+	deleteExtra     bool   // push-sync
+	searchDL        bool   // search
+}
+
+// register defines the flags of name on fs.
+func (c *commandFlags) register(fs *flag.FlagSet, name string) {
+	switch name {
+	case "ls":
+		fs.BoolVar(&c.long, "l", false, "Long listing with size, type and ID")
+		fs.BoolVar(&c.recursive, "R", false, "List subfolders recursively")
+	case "put":
+		fs.StringVar(&c.parent, "parent", "", "Upload into this Drive folder (URL or ID) instead of My Drive")
+	case "push-sync":
+		fs.BoolVar(&c.deleteExtra, "delete", false, "Move remote files that are missing locally to the Drive trash")
+	case "search":
+		fs.BoolVar(&c.searchDL, "download", false, "Download the matching files")
+	}
+}
+
+// printUsage writes the usage line of every command.
+func printUsage(w io.Writer) {
+	for i, c := range commands {
+		prefix := "       "
+		if i == 0 {
+			prefix = "Usage: "
+		}
+		fmt.Fprintln(w, prefix+c.usage)
+		if c.name == "get" {
+			fmt.Fprintln(w, "       gget -clipboard")
+		}
+	}
+}
+
+// runHelp implements `gget help`. Without arguments it lists the
+// commands; with one it shows that command's usage and its own flags,
+// and for get every download flag.
+func runHelp(args []string) error {
+	if len(args) == 0 {
+		printUsage(os.Stdout)
+		fmt.Println("\nCommands:")
+		for _, c := range commands {
+			fmt.Printf("  %-11s %s\n", c.name, c.summary)
+		}
+		fmt.Println("\nRun gget help <command> for the flags of a command.")
+		return nil
+	}
+
+	c := findCommand(args[0])
+	if c == nil {
+		return fmt.Errorf("unknown command %q; run gget help for the list", args[0])
+	}
+	fmt.Printf("Usage: %s\n\n%s.\n", c.usage, c.summary)
+
+	switch c.name {
+	case "get":
+		fmt.Println("\nFlags:")
+		flag.CommandLine.SetOutput(os.Stdout)
+		flag.PrintDefaults()
+	case "info", "ls", "revisions", "verify", "put", "push-sync", "search", "sync":
+		fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+		new(commandFlags).register(fs, c.name)
+		var own bool
+		fs.VisitAll(func(*flag.Flag) { own = true })
+		if own {
+			fmt.Println("\nFlags:")
+			fs.SetOutput(os.Stdout)
+			fs.PrintDefaults()
+		}
+		fmt.Println("\nIt also takes the download and connection flags; see gget help get.")
+	}
+	return nil
+}
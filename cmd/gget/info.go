@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/phx/gget"
+)
+
+// runInfo implements `gget info`, printing the metadata of each URL
+// without downloading it.
+func runInfo(ctx context.Context, downloader *gget.Client, urls []string, opts gget.Options, jsonOut bool) error {
+	if len(urls) == 0 {
+		return fmt.Errorf("usage: gget info <google_drive_url>...")
+	}
+
+	var failed int
+	var first error
+	for i, url := range urls {
+		info, err := downloader.Info(ctx, url, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", url, err)
+			failed++
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+
+		if jsonOut {
+			data, _ := json.Marshal(info)
+			fmt.Println(string(data))
+			continue
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		printInfo(info)
+	}
+
+	if failed > 0 {
+		return &summaryError{fmt.Sprintf("%d of %d lookups failed", failed, len(urls)), first}
+	}
+	return nil
+}
+
+func printInfo(info *gget.FileInfo) {
+	fmt.Printf("Name:     %s\n", info.Name)
+	if info.ID != "" {
+		fmt.Printf("ID:       %s\n", info.ID)
+	}
+	if info.Size >= 0 {
+		fmt.Printf("Size:     %s (%d bytes)\n", gget.FormatSize(info.Size), info.Size)
+	} else {
+		fmt.Println("Size:     unknown")
+	}
+	if info.MimeType != "" {
+		fmt.Printf("Type:     %s\n", info.MimeType)
+	}
+	if info.MD5 != "" {
+		fmt.Printf("MD5:      %s\n", info.MD5)
+	}
+	if info.Owner != "" {
+		fmt.Printf("Owner:    %s\n", info.Owner)
+	}
+	if !info.Modified.IsZero() {
+		fmt.Printf("Modified: %s\n", info.Modified.Local().Format(time.DateTime))
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/phx/gget"
+)
+
+// runList implements `gget ls`, listing the items of a folder.
+func runList(ctx context.Context, downloader *gget.Client, args []string, long, recursive, jsonOut bool) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: gget ls [-l] [-R] <folder_url>")
+	}
+
+	items, err := downloader.ListFolder(ctx, args[0], recursive)
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		for _, item := range items {
+			data, _ := json.Marshal(item)
+			fmt.Println(string(data))
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, item := range items {
+		name := item.Name
+		if recursive {
+			name = item.Path
+		}
+		if item.IsFolder() {
+			name += "/"
+		}
+
+		if !long {
+			fmt.Fprintln(w, name)
+			continue
+		}
+
+		size := "-"
+		if item.Size >= 0 && !item.IsFolder() {
+			size = gget.FormatSize(item.Size)
+		}
+		mimeType := item.MimeType
+		if mimeType == "" {
+			mimeType = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.ID, size, mimeType, name)
+	}
+	return w.Flush()
+}
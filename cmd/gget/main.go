@@ -0,0 +1,781 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"iter"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/phx/gget"
+)
+
+// EXIT_INTERRUPTED is returned when a download is stopped by SIGINT or
+// SIGTERM; its .part file is kept for resuming with -c.
+const EXIT_INTERRUPTED = 130
+
+// headerFlags collects repeated -H "Name: value" flags.
+type headerFlags []string
+
+func (h *headerFlags) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headerFlags) Set(value string) error {
+	if name, _, ok := strings.Cut(value, ":"); !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header must be in \"Name: value\" form")
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+// listFlags collects the values of a repeatable flag.
+type listFlags []string
+
+func (l *listFlags) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *listFlags) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	var (
+		outputFile = flag.String("o", "", "Output filename (- for stdout)")
+		quiet      = flag.Bool("q", false, "Quiet mode (no progress)")
+		debug      = flag.Bool("debug", false, "Print each request's redirects and DNS, connect, TLS and first-byte timings")
+		noCheck    = flag.Bool("no-check-certificate", false, "Skip certificate verification")
+		caCert     = flag.String("ca-certificate", "", "Also trust the PEM CA certificates in this file")
+		clientCert = flag.String("client-cert", "", "Present this PEM client certificate to servers that require one")
+		clientKey  = flag.String("client-key", "", "Private key for -client-cert, if not in the same file")
+		tlsMin     = flag.String("tls-min", "", "Minimum TLS version: 1.0, 1.1, 1.2 or 1.3")
+		ciphers    = flag.String("ciphers", "", "Comma-separated TLS 1.2 cipher suites to allow, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
+		noHTTP2    = flag.Bool("no-http2", false, "Use HTTP/1.1 only")
+		ipv4       = flag.Bool("4", false, "Connect over IPv4 only")
+		ipv6       = flag.Bool("6", false, "Connect over IPv6 only")
+		version    = flag.Bool("V", false, "Show version")
+		fileID     = flag.String("id", "", "Google Drive file ID")
+		maxHTML    = flag.String("max-html-size", "10M", "Maximum size of an HTML confirmation page to read")
+		direct     = flag.Bool("direct-media", false, "Stream an already-resolved https media URL as-is")
+		retryTrunc = flag.Bool("retry-on-truncation", true, "Resume a transfer that ends short of Content-Length; set to false to fail instead")
+		noResume   = flag.Bool("no-retry-on-truncation", false, "Fail instead of resuming a transfer that ends short of Content-Length")
+		chmod      = flag.String("chmod", "", "Set permissions on the downloaded file (e.g. 0644 or +x)")
+		xattr      = flag.Bool("xattr", false, "Record the source URL and Drive file ID in the downloaded file's extended attributes (Linux only)")
+		chown      = flag.String("chown", "", "Give downloaded files to user[:group] (needs root)")
+		organize   = flag.Bool("organize-by-type", false, "Sort downloads into images/, docs/, archives/, ... subfolders")
+		checkFile  = flag.String("checkpoint", "", "Record completed URLs in this file and skip them on later runs")
+		restart    = flag.Bool("restart", false, "Ignore an existing checkpoint file")
+		chunks     = flag.Int("chunks", 1, "Download large files as N concurrent byte ranges")
+		inputFile  = flag.String("i", "", "Download every URL or file ID listed in this file (- for stdin)")
+		clipboard  = flag.Bool("clipboard", false, "Download the URLs or file IDs in the system clipboard")
+		tokStore   = flag.String("token-store", "auto", "Where the gget auth login token is kept: keyring, file or auto")
+		saKey      = flag.String("service-account", "", "Authenticate with a Google service account JSON key")
+		format     = flag.String("format", "", "Export format for Docs/Sheets/Slides/Drawings (pdf, docx, xlsx, csv, pptx, odt, png, svg, ...)")
+		noVerify   = flag.Bool("no-verify", false, "Skip MD5 verification of the downloaded file")
+		limitRate  = flag.String("limit-rate", "", "Limit download speed in bytes per second (e.g. 500K, 2M)")
+		rateSched  = flag.String("limit-rate-schedule", "", "Vary -limit-rate by local time of day, e.g. \"08:00-18:00=1M,18:00-08:00=0\" (0 for unlimited)")
+		proxy      = flag.String("proxy", "", "Proxy URL (http://, https:// or socks5://[user:pass@]host:port)")
+		cookieFile = flag.String("cookies", "", "Load cookies from a Netscape-format cookies.txt file")
+		netrcFile  = flag.String("netrc-file", "", "Read host credentials for non-Drive URLs from this file instead of ~/.netrc")
+		userAgent  = flag.String("user-agent", "", "Override the User-Agent header")
+		referer    = flag.String("referer", "", "Send this URL as the Referer header")
+		bearer     = flag.String("bearer", "", "Send this token as \"Authorization: Bearer\" to non-Drive URLs")
+		bearerAPI  = flag.Bool("bearer-drive", false, "Also use the -bearer token for the Drive API")
+		basicAuth  = flag.String("basic", "", "Send user:password with HTTP basic authentication to non-Drive URLs")
+		retries    = flag.Int("retries", gget.MAX_RETRY_COUNT, "Retry failed requests and transfers up to N times")
+		wait       = flag.Duration("wait", 0, "Wait this long between the files of a batch or folder, also with -j (e.g. 5s)")
+		randWait   = flag.Bool("random-wait", false, "Vary -wait between half and one and a half times it")
+		retryWait  = flag.Duration("retry-wait", time.Second, "Initial delay between retries, doubled after each attempt")
+		connTime   = flag.Duration("connect-timeout", gget.CONNECT_TIMEOUT, "Give up on opening a connection after this long")
+		maxTime    = flag.Duration("max-time", 0, "Give up on each download after this long (default no limit)")
+		minSpeed   = flag.String("min-speed", "", "Reconnect and resume when a transfer averages less than this many bytes per second over -min-speed-time (e.g. 50K)")
+		minSpeedT  = flag.Duration("min-speed-time", gget.MIN_SPEED_TIME, "Period over which -min-speed is measured")
+		readIdle   = flag.Duration("read-timeout", gget.READ_TIMEOUT, "Reconnect and resume when a transfer receives no data for this long (0 to disable)")
+		retryQuota = flag.Duration("retry-quota", 0, "Keep retrying a file whose download quota is exceeded for up to this long (e.g. 6h)")
+		skipExist  = flag.Bool("skip-existing", false, "Skip files whose local copy matches the remote size and MD5")
+		dryRun     = flag.Bool("dry-run", false, "Show what would be downloaded, with sizes and destinations, without writing anything")
+		noClobber  = flag.Bool("no-clobber", false, "Skip downloads whose output file already exists")
+		autoRename = flag.Bool("auto-rename", false, "Save as \"name (1).ext\" when the output file already exists")
+		force      = flag.Bool("force", false, "Overwrite existing output files (the default), including the complete files of a folder being downloaded again")
+		normalize  = flag.String("normalize-filenames", "", "Unicode form of local file names: nfc, nfd or none (default)")
+		trustNames = flag.Bool("trust-server-names", false, "Use file names from the server as-is, even ones with directories or \"..\" that lead outside the output directory")
+		restrict   = flag.String("restrict-filenames", "", "Clean remote file names for: none (default), windows or ascii")
+		extract    = flag.Bool("extract", false, "Unpack .zip, .tar and .tar.gz downloads into the output directory")
+		rmArchive  = flag.Bool("remove-archive", false, "Delete archives after -extract unpacks them")
+		noSpace    = flag.Bool("no-space-check", false, "Download even if the destination seems to lack free space")
+		confirmAt  = flag.String("confirm-size", "", "Ask before downloading a file or folder larger than this (e.g. 1G)")
+		yes        = flag.Bool("yes", false, "Answer yes to -confirm-size prompts")
+		withTrash  = flag.Bool("include-trashed", false, "List and download folder files that are in the Drive trash (needs gget auth login)")
+		noShortcut = flag.Bool("no-follow-shortcuts", false, "Don't download the targets of Drive shortcuts")
+		writeMeta  = flag.Bool("write-metadata", false, "Save each file's Drive metadata (ID, type, MD5, owners, dates, description) as file.info.json")
+		checksum   = flag.String("write-checksum", "", "Write file.<algorithm> with the md5, sha1, sha256 or sha512 of each download")
+		driveOnly  = flag.Bool("drive-only", false, "Fail on URLs that are not Google Drive links instead of downloading them directly")
+		asZip      = flag.Bool("as-zip", false, "Save a folder as one .zip archive, built while downloading")
+		execCmd    = flag.String("exec", "", "Run this shell command after each download; {} is the file's path, {id} its file ID and {name} its name")
+		manifest   = flag.String("manifest", "", "Append a JSON line per finished file (URL, ID, name, size, MD5, time, duration) to this file")
+		notifyURL  = flag.String("notify-url", "", "POST a JSON summary to this URL (e.g. a Slack webhook) when each download finishes or fails")
+		outTmpl    = flag.String("output-template", "", "Name downloads after this template, e.g. '{id}_{name}'; variables: {name}, {id}, {ext}, {date}, {index}")
+		byteRange  = flag.String("range", "", "Download only these bytes of the file, e.g. 0-1048575 or 1000- (inclusive, as in HTTP)")
+		revision   = flag.String("revision", "", "Download this revision of the file (see gget revisions; needs gget auth login)")
+		configArg  = flag.String("config", "", "Read default flag values from this file (default ~/.config/gget/config.yaml)")
+		jobs       = flag.Int("j", 1, "Download up to N files concurrently")
+		tuiMode    = flag.Bool("tui", false, "Show a live table of queued, active and finished files instead of the progress line")
+		jsonOut    = flag.Bool("json", false, "Print JSON events (start, progress, done, error) instead of progress output")
+		progStyle  = flag.String("progress", "bar", "Progress output: bar, or json for one JSON record per second on stderr")
+		progressFD = flag.Int("progress-fd", 0, "Write JSON progress records to this file descriptor (e.g. 3) instead of stderr")
+		resume     bool
+		timestamps bool
+		prefix     string
+		printURL   bool
+		headers    headerFlags
+		include    listFlags
+		exclude    listFlags
+		resolve    listFlags
+	)
+	flag.Var(&headers, "H", "Add a request header \"Name: value\" (repeatable)")
+	flag.Var(&include, "include", "Only download folder files whose path matches this glob, e.g. '*.csv' (repeatable)")
+	flag.Var(&exclude, "exclude", "Skip folder files whose path matches this glob, e.g. 'raw/**' (repeatable)")
+	flag.Var(&resolve, "resolve", "Connect to host:port at this address instead of looking it up, as host:port:address (repeatable)")
+	flag.BoolVar(&resume, "c", false, "Resume a partially downloaded file")
+	flag.BoolVar(&resume, "continue", false, "Resume a partially downloaded file")
+	flag.StringVar(&prefix, "P", "", "Save files under this directory, keeping their remote names")
+	flag.StringVar(&prefix, "directory-prefix", "", "Save files under this directory, keeping their remote names")
+	flag.BoolVar(&timestamps, "N", false, "Keep the remote modification time and skip files whose local copy is not older")
+	flag.BoolVar(&timestamps, "timestamps", false, "Keep the remote modification time and skip files whose local copy is not older")
+	flag.BoolVar(&printURL, "print-url", false, "Print the resolved download URL (and any cookies it needs) instead of downloading")
+	flag.BoolVar(&printURL, "no-download", false, "Print the resolved download URL (and any cookies it needs) instead of downloading")
+
+	if len(os.Args) > 1 && os.Args[1] == "version" {
+		fmt.Println("gget version " + VERSION)
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "help" {
+		if err := runHelp(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(EXIT_USAGE)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "completion" {
+		if err := runCompletion(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "auth" {
+		if err := runAuth(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+
+	// Subcommands that work on remote files share the download flags
+	command, args := "", os.Args[1:]
+	var job *jobQueue
+	if len(args) > 0 && args[0] == "resume" {
+		var err error
+		if job, err = loadQueue(QUEUE_FILE); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		// Continue partial files and keep the finished files of folders;
+		// flags given to resume, such as credentials, come first so that
+		// they are parsed before the recorded URLs
+		command, args = job.Command, slices.Concat([]string{"-c", "-skip-existing"}, args[1:], job.Args)
+	} else if len(args) > 0 && args[0] == "get" {
+		// get is the default command
+		args = args[1:]
+	} else if len(args) > 0 && (args[0] == "info" || args[0] == "ls" || args[0] == "revisions" || args[0] == "sync" || args[0] == "verify" || args[0] == "put" || args[0] == "push-sync" || args[0] == "search") {
+		command, args = args[0], args[1:]
+	}
+	var cmdFlags commandFlags
+	cmdFlags.register(flag.CommandLine, command)
+	flag.CommandLine.Parse(args)
+	// Flags on the command line override the config file
+	configFile, explicit := *configArg, true
+	if configFile == "" {
+		configFile, explicit = defaultConfigPath(), false
+	}
+	if err := loadConfig(configFile, explicit); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *version {
+		fmt.Println("gget version " + VERSION)
+		return
+	}
+
+	if *jsonOut {
+		*quiet = true
+	}
+
+	downloader := gget.New()
+	downloader.Quiet = *quiet
+	downloader.Debug = *debug
+	if *outputFile == "-" {
+		// Keep stdout for the file itself
+		downloader.Log = os.Stderr
+	}
+	if *jsonOut {
+		downloader.Events = downloader.Log
+	}
+	downloader.RetryOnTruncation = *retryTrunc && !*noResume
+	downloader.OrganizeByType = *organize
+	downloader.Resume = resume
+	downloader.Chunks = *chunks
+	downloader.ExportFormat = strings.ToLower(*format)
+	downloader.Verify = !*noVerify
+	downloader.Retries = *retries
+	downloader.RetryWait = *retryWait
+	downloader.Wait = *wait
+	downloader.RandomWait = *randWait
+	downloader.ReadTimeout = *readIdle
+	downloader.MaxTime = *maxTime
+	downloader.SetConnectTimeout(*connTime)
+	downloader.QuotaWait = *retryQuota
+	downloader.SkipExisting = *skipExist || command == "sync"
+	downloader.DryRun = *dryRun
+	downloader.Timestamps = timestamps
+	downloader.Directory = prefix
+	downloader.Extract = *extract
+	downloader.RemoveArchive = *rmArchive
+	downloader.SpaceCheck = !*noSpace
+	downloader.WriteMetadata = *writeMeta
+	downloader.Xattrs = *xattr
+	downloader.TrustServerNames = *trustNames
+	downloader.FolderResume = !*force
+	downloader.IncludeTrashed = *withTrash
+	downloader.KeepShortcuts = *noShortcut
+	downloader.FolderZip = *asZip
+	downloader.DriveOnly = *driveOnly
+	switch {
+	case *force:
+		downloader.Clobber = gget.OVERWRITE
+	case *noClobber && *autoRename:
+		fmt.Fprintln(os.Stderr, "Error: -no-clobber cannot be combined with -auto-rename")
+		os.Exit(EXIT_USAGE)
+	case *noClobber:
+		downloader.Clobber = gget.NO_CLOBBER
+	case *autoRename:
+		downloader.Clobber = gget.AUTO_RENAME
+	}
+
+	maxHTMLSize, err := gget.ParseSize(*maxHTML)
+	if err != nil || maxHTMLSize <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: invalid -max-html-size %q\n", *maxHTML)
+		os.Exit(EXIT_USAGE)
+	}
+	downloader.MaxHTMLSize = maxHTMLSize
+
+	if downloader.RestrictFilenames, err = gget.ParseFilenameRestriction(*restrict); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(EXIT_USAGE)
+	}
+	if downloader.NormalizeNames, err = gget.ParseFilenameNormalization(*normalize); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(EXIT_USAGE)
+	}
+
+	if downloader.ChecksumFile, err = gget.ParseChecksumAlgorithm(*checksum); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(EXIT_USAGE)
+	}
+
+	if downloader.ProgressJSON, err = progressWriter(*progStyle, *progressFD); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(EXIT_USAGE)
+	}
+
+	if err := downloader.SetFilters(include, exclude); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(EXIT_USAGE)
+	}
+
+	if *limitRate != "" {
+		rate, err := gget.ParseSize(*limitRate)
+		if err != nil || rate <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: invalid -limit-rate %q\n", *limitRate)
+			os.Exit(EXIT_USAGE)
+		}
+		downloader.SetRateLimit(rate)
+	}
+	if *rateSched != "" {
+		if err := downloader.SetRateSchedule(*rateSched); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -limit-rate-schedule: %v\n", err)
+			os.Exit(EXIT_USAGE)
+		}
+	}
+
+	if *minSpeed != "" {
+		speed, err := gget.ParseSize(*minSpeed)
+		if err != nil || speed <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: invalid -min-speed %q\n", *minSpeed)
+			os.Exit(EXIT_USAGE)
+		}
+		downloader.MinSpeed = speed
+		downloader.MinSpeedTime = *minSpeedT
+	}
+
+	if err := downloader.SetOutputTemplate(*outTmpl); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid -output-template: %v\n", err)
+		os.Exit(EXIT_USAGE)
+	}
+
+	var slice *gget.ByteRange
+	if *byteRange != "" {
+		if slice, err = gget.ParseByteRange(*byteRange); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -range: %v\n", err)
+			os.Exit(EXIT_USAGE)
+		}
+	}
+
+	if *confirmAt != "" && !*yes {
+		limit, err := gget.ParseSize(*confirmAt)
+		if err != nil || limit <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: invalid -confirm-size %q\n", *confirmAt)
+			os.Exit(EXIT_USAGE)
+		}
+		downloader.ConfirmSize = limit
+		downloader.Confirm = confirmPrompt
+	}
+
+	if *chmod != "" {
+		if downloader.Chmod, err = gget.ParseChmod(*chmod); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -chmod: %v\n", err)
+			os.Exit(EXIT_USAGE)
+		}
+	}
+	if *chown != "" {
+		if os.Geteuid() != 0 {
+			fmt.Fprintln(os.Stderr, "Error: -chown needs to run as root")
+			os.Exit(1)
+		}
+		if downloader.Chown, err = gget.ParseOwner(*chown); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -chown: %v\n", err)
+			os.Exit(EXIT_USAGE)
+		}
+	}
+
+	if err := setTokenStore(*tokStore); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(EXIT_USAGE)
+	}
+	if *saKey != "" && (command == "put" || command == "push-sync") {
+		err = downloader.UseServiceAccount(*saKey, gget.DRIVE_WRITE_SCOPE)
+	} else if *saKey != "" {
+		err = downloader.UseServiceAccount(*saKey)
+	} else {
+		err = downloader.UseSavedLogin()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Handle certificate verification
+	if *noCheck {
+		downloader.TLSConfig().InsecureSkipVerify = true
+	}
+	if *caCert != "" {
+		if err := downloader.SetCACertificates(*caCert); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *clientCert != "" {
+		if err := downloader.SetClientCertificate(*clientCert, *clientKey); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else if *clientKey != "" {
+		fmt.Fprintln(os.Stderr, "Error: -client-key requires -client-cert")
+		os.Exit(EXIT_USAGE)
+	}
+	if *tlsMin != "" {
+		version, err := gget.ParseTLSVersion(*tlsMin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(EXIT_USAGE)
+		}
+		downloader.TLSConfig().MinVersion = version
+	}
+	if *ciphers != "" {
+		if err := downloader.SetCipherSuites(strings.Split(*ciphers, ",")); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(EXIT_USAGE)
+		}
+	}
+	if *noHTTP2 {
+		downloader.DisableHTTP2()
+	}
+
+	switch {
+	case *ipv4 && *ipv6:
+		fmt.Fprintln(os.Stderr, "Error: -4 cannot be combined with -6")
+		os.Exit(EXIT_USAGE)
+	case *ipv4:
+		downloader.SetIPVersion(4)
+	case *ipv6:
+		downloader.SetIPVersion(6)
+	}
+	for _, spec := range resolve {
+		if err := downloader.AddResolve(spec); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(EXIT_USAGE)
+		}
+	}
+
+	for _, header := range headers {
+		name, value, _ := strings.Cut(header, ":")
+		downloader.Headers[http.CanonicalHeaderKey(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+	if *userAgent != "" {
+		downloader.Headers["User-Agent"] = *userAgent
+	}
+	if *referer != "" {
+		downloader.Headers["Referer"] = *referer
+	}
+	switch {
+	case *bearer != "" && *basicAuth != "":
+		fmt.Fprintln(os.Stderr, "Error: -bearer cannot be combined with -basic")
+		os.Exit(EXIT_USAGE)
+	case *bearer != "":
+		downloader.HostAuth = "Bearer " + *bearer
+	case *bearerAPI:
+		fmt.Fprintln(os.Stderr, "Error: -bearer-drive needs -bearer")
+		os.Exit(EXIT_USAGE)
+	case *basicAuth != "":
+		if !strings.Contains(*basicAuth, ":") {
+			fmt.Fprintln(os.Stderr, "Error: -basic must be user:password")
+			os.Exit(EXIT_USAGE)
+		}
+		downloader.HostAuth = "Basic " + base64.StdEncoding.EncodeToString([]byte(*basicAuth))
+	}
+
+	if *bearerAPI {
+		downloader.Auth = gget.StaticToken(*bearer)
+	}
+
+	if path := netrcPath(*netrcFile); path != "" {
+		if err := downloader.LoadNetrc(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *cookieFile != "" {
+		if err := downloader.LoadCookies(*cookieFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *proxy != "" {
+		if err := downloader.SetProxy(*proxy); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(EXIT_USAGE)
+		}
+	}
+
+	if command == "info" || command == "ls" || command == "revisions" || command == "verify" || command == "put" || command == "push-sync" || command == "search" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		switch command {
+		case "ls":
+			err = runList(ctx, downloader, flag.Args(), cmdFlags.long, cmdFlags.recursive, *jsonOut)
+		case "revisions":
+			err = runRevisions(ctx, downloader, flag.Args(), *jsonOut)
+		case "verify":
+			err = runVerify(ctx, downloader, flag.Args(), *jsonOut)
+		case "put":
+			err = runPut(ctx, downloader, flag.Args(), cmdFlags.parent, *jsonOut)
+		case "push-sync":
+			err = runPushSync(ctx, downloader, flag.Args(), cmdFlags.deleteExtra)
+		case "search":
+			err = runSearch(ctx, downloader, flag.Args(), cmdFlags.searchDL, *jsonOut)
+		default:
+			err = runInfo(ctx, downloader, flag.Args(), gget.Options{DirectMedia: *direct}, *jsonOut)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+
+	var entries []batchEntry
+	var stream iter.Seq[batchEntry]
+	fromStdin := *inputFile == "-" ||
+		(*inputFile == "" && !*clipboard && command == "" && *fileID == "" && flag.NArg() == 0 && stdinIsPiped())
+	if fromStdin {
+		if *outputFile != "" {
+			fmt.Fprintln(os.Stderr, "Error: -o cannot be combined with -i")
+			os.Exit(EXIT_USAGE)
+		}
+		stream = stdinEntries()
+		if *checkFile == "" {
+			*checkFile = DEFAULT_CHECKPOINT_FILE
+		}
+	} else if *inputFile != "" {
+		if *outputFile != "" {
+			fmt.Fprintln(os.Stderr, "Error: -o cannot be combined with -i")
+			os.Exit(EXIT_USAGE)
+		}
+		if entries, err = readBatchFile(*inputFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if *checkFile == "" {
+			*checkFile = DEFAULT_CHECKPOINT_FILE
+		}
+	} else if *clipboard {
+		if flag.NArg() > 0 || *fileID != "" || command != "" {
+			fmt.Fprintln(os.Stderr, "Error: -clipboard cannot be combined with URL arguments, -id or a command")
+			os.Exit(EXIT_USAGE)
+		}
+		urls, err := readClipboard()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(urls) > 1 && *outputFile != "" {
+			fmt.Fprintln(os.Stderr, "Error: -o cannot be used with more than one URL")
+			os.Exit(EXIT_USAGE)
+		}
+		for _, url := range urls {
+			entries = append(entries, batchEntry{URL: url, Output: *outputFile})
+		}
+		args = append(dropFlag(args, "clipboard"), urls...)
+	} else if command == "sync" {
+		if flag.NArg() == 0 || flag.NArg() > 2 {
+			fmt.Fprintln(os.Stderr, "Error: usage: gget sync <folder_url> [directory]")
+			os.Exit(EXIT_USAGE)
+		}
+		entries = []batchEntry{{URL: flag.Arg(0), Output: flag.Arg(1)}}
+	} else if *fileID != "" {
+		entries = []batchEntry{{URL: *fileID, Output: *outputFile}}
+	} else if flag.NArg() > 0 {
+		if flag.NArg() > 1 && *outputFile != "" {
+			fmt.Fprintln(os.Stderr, "Error: -o cannot be used with more than one URL")
+			os.Exit(EXIT_USAGE)
+		}
+		for _, url := range flag.Args() {
+			entries = append(entries, batchEntry{URL: url, Output: *outputFile})
+		}
+	} else {
+		printUsage(os.Stdout)
+		os.Exit(EXIT_USAGE)
+	}
+
+	var logs []doneLog
+	if *checkFile != "" && !*dryRun {
+		progress, err := loadCheckpoint(*checkFile, *restart)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		logs = append(logs, progress)
+	}
+
+	// Record batch jobs so that `gget resume` can pick them up
+	if job == nil && !*dryRun && !printURL && *outputFile != "-" && !fromStdin &&
+		(*inputFile != "" || command == "sync" || len(entries) > 1 || gget.IsFolderURL(entries[0].URL)) {
+		if job, err = newQueue(QUEUE_FILE, command, args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if job != nil {
+		logs = append(logs, job)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	handlePauseSignals(downloader)
+
+	// Hooks run in order for each finished file
+	var notify *notifier
+	var onDone []func(gget.Event) error
+	if *notifyURL != "" && !printURL && !*dryRun {
+		notify = newNotifier(*notifyURL, downloader.Transport)
+		onDone = append(onDone, func(event gget.Event) error {
+			notify.done(event)
+			return nil
+		})
+	}
+	if *manifest != "" && !printURL && !*dryRun {
+		records, err := openManifest(*manifest)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		onDone = append(onDone, records.record)
+	}
+	if *execCmd != "" && !*dryRun {
+		onDone = append(onDone, func(event gget.Event) error {
+			return runExec(*execCmd, event)
+		})
+	}
+
+	var begun atomic.Bool
+	download := func(entry batchEntry) error {
+		if begun.Swap(true) && !*dryRun && !printURL {
+			if err := downloader.WaitBetween(ctx); err != nil {
+				return err
+			}
+		}
+		url := entry.URL
+		opts := gget.Options{Output: entry.Output, DirectMedia: *direct, SkipDownload: printURL, Revision: *revision, Range: slice, Index: entry.Index}
+		var hookErr error
+		if len(onDone) > 0 {
+			opts.OnDone = func(event gget.Event) {
+				for _, hook := range onDone {
+					if err := hook(event); err != nil && hookErr == nil {
+						hookErr = err
+					}
+				}
+			}
+		}
+		if err := downloader.Download(ctx, url, opts); err != nil {
+			if notify != nil {
+				notify.failed(url, err)
+			}
+			return err
+		}
+		return hookErr
+	}
+
+	var screen *tui
+	if *tuiMode && !*dryRun && !printURL {
+		if *jsonOut || *outputFile == "-" || !canUseTUI() {
+			fmt.Fprintln(os.Stderr, "Error: -tui needs a terminal on stdout and cannot be combined with -json or -o -")
+			os.Exit(EXIT_USAGE)
+		}
+		queued := -1
+		if stream == nil {
+			queued = 0
+			for _, entry := range entries {
+				if !isDone(logs, entry.key()) {
+					queued++
+				}
+			}
+		}
+		screen = newTUI(os.Stdout, queued)
+		downloader.Events, downloader.Log = screen.events(), screen.logger()
+		fetch := download
+		download = func(entry batchEntry) error {
+			screen.begin()
+			err := fetch(entry)
+			screen.end(err)
+			return err
+		}
+	}
+
+	if stream == nil {
+		stream = slices.Values(entries)
+	}
+	started := time.Now()
+	result := runBatch(stream, download, logs, *quiet || screen != nil, screen == nil, *jobs)
+	if screen != nil {
+		screen.stop()
+		downloader.Events, downloader.Log = nil, os.Stdout
+	}
+	if *dryRun {
+		files, bytes := downloader.DryRunTotal()
+		fmt.Printf("\nWould download %d files, %s in total\n", files, gget.FormatSize(bytes))
+	} else if (*inputFile != "" || fromStdin || len(entries) > 1) && !*quiet {
+		result.printSummary()
+	}
+	if !*dryRun && !printURL {
+		stats := downloader.Stats()
+		stats.Skipped += result.skipped
+		if *jsonOut {
+			downloader.EmitSummary(stats, time.Since(started))
+		} else if !*quiet {
+			printStats(downloader.Log, stats, time.Since(started))
+		}
+	}
+	if result.interrupted {
+		if job != nil {
+			fmt.Fprintln(os.Stderr, "Interrupted; run gget resume to continue")
+		} else {
+			fmt.Fprintln(os.Stderr, "Interrupted; run the same command with -c to resume")
+		}
+		os.Exit(EXIT_INTERRUPTED)
+	}
+	if job != nil && len(result.failed) == 0 {
+		job.remove()
+	}
+	if len(result.failed) > 0 {
+		os.Exit(exitCode(result.firstErr))
+	}
+}
+
+// runAuth implements the `gget auth` subcommands.
+func runAuth(args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("usage: gget auth login|logout")
+	}
+
+	switch args[0] {
+	case "login":
+		flags := flag.NewFlagSet("auth login", flag.ExitOnError)
+		clientID := flags.String("client-id", os.Getenv("GGET_CLIENT_ID"), "OAuth2 client ID (or GGET_CLIENT_ID)")
+		clientSecret := flags.String("client-secret", os.Getenv("GGET_CLIENT_SECRET"), "OAuth2 client secret (or GGET_CLIENT_SECRET)")
+		write := flags.Bool("write", false, "Also allow changes to Drive, as gget put needs")
+		store := flags.String("token-store", "auto", "Keep the token in the system keyring, a file, or the keyring when available (keyring, file or auto)")
+		flags.Parse(args[1:])
+		if err := setTokenStore(*store); err != nil {
+			return err
+		}
+
+		if *clientID == "" || *clientSecret == "" {
+			return usageErrorf("an OAuth2 desktop client is required: pass -client-id and -client-secret")
+		}
+		if *write {
+			return gget.New().Login(*clientID, *clientSecret, gget.DRIVE_WRITE_SCOPE)
+		}
+		return gget.New().Login(*clientID, *clientSecret)
+	case "logout":
+		flags := flag.NewFlagSet("auth logout", flag.ExitOnError)
+		store := flags.String("token-store", "auto", "Remove the token from the keyring, the file, or both (keyring, file or auto)")
+		flags.Parse(args[1:])
+		if err := setTokenStore(*store); err != nil {
+			return err
+		}
+		if err := gget.Logout(); err != nil {
+			return err
+		}
+		fmt.Println("Logged out")
+		return nil
+	}
+
+	return usageErrorf("unknown auth command %q", args[0])
+}
+
+// setTokenStore applies a -token-store value.
+func setTokenStore(store string) error {
+	storage, err := gget.ParseTokenStore(store)
+	if err != nil {
+		return &usageError{err.Error()}
+	}
+	gget.TokenStorage = storage
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/phx/gget"
+)
+
+// manifestRecord is one line of a -manifest file.
+type manifestRecord struct {
+	URL      string  `json:"url"`
+	ID       string  `json:"id,omitempty"`
+	File     string  `json:"file"`
+	Size     int64   `json:"size"`
+	MD5      string  `json:"md5"`
+	Time     string  `json:"time"`     // when the file finished, in RFC 3339
+	Duration float64 `json:"duration"` // seconds
+}
+
+// manifestLog appends a manifestRecord per finished file, one JSON object
+// per line, so that earlier runs' records are kept.
+type manifestLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func openManifest(path string) (*manifestLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %v", err)
+	}
+	return &manifestLog{file: file}, nil
+}
+
+func (m *manifestLog) record(event gget.Event) error {
+	data, _ := json.Marshal(manifestRecord{
+		URL:      gget.PublicURL(event.URL),
+		ID:       event.ID,
+		File:     event.Name,
+		Size:     event.Size,
+		MD5:      event.MD5,
+		Time:     time.Now().Format(time.RFC3339),
+		Duration: event.Duration,
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := m.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/phx/gget"
+)
+
+const NOTIFY_TIMEOUT = 30 * time.Second
+
+// notification is the JSON body POSTed to -notify-url.
+type notification struct {
+	Status   string  `json:"status"` // done or failed
+	URL      string  `json:"url"`
+	ID       string  `json:"id,omitempty"`
+	File     string  `json:"file,omitempty"`
+	Size     int64   `json:"size,omitempty"`
+	Duration float64 `json:"duration,omitempty"` // seconds
+	MD5      string  `json:"md5,omitempty"`
+	Error    string  `json:"error,omitempty"`
+	// Text is a one-line summary, which is what Slack and similar
+	// incoming webhooks display.
+	Text string `json:"text"`
+}
+
+// notifier posts a notification for each finished or failed download.
+type notifier struct {
+	url    string
+	client *http.Client
+}
+
+func newNotifier(url string, transport http.RoundTripper) *notifier {
+	return &notifier{url: url, client: &http.Client{Transport: transport, Timeout: NOTIFY_TIMEOUT}}
+}
+
+func (n *notifier) done(event gget.Event) {
+	n.post(notification{
+		Status:   "done",
+		URL:      event.URL,
+		ID:       event.ID,
+		File:     event.Name,
+		Size:     event.Size,
+		Duration: event.Duration,
+		MD5:      event.MD5,
+		Text:     fmt.Sprintf("gget: downloaded %s (%s)", event.Name, gget.FormatSize(event.Size)),
+	})
+}
+
+func (n *notifier) failed(url string, err error) {
+	n.post(notification{
+		Status: "failed",
+		URL:    url,
+		ID:     gget.ExtractFileID(url),
+		Error:  err.Error(),
+		Text:   fmt.Sprintf("gget: failed to download %s: %v", url, err),
+	})
+}
+
+// post sends body, reporting but otherwise ignoring failures so that an
+// unreachable webhook doesn't fail the download.
+func (n *notifier) post(body notification) {
+	data, _ := json.Marshal(body)
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -notify-url: %v\n", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		fmt.Fprintf(os.Stderr, "Error: -notify-url: %s\n", resp.Status)
+	}
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/phx/gget"
+)
+
+// handlePauseSignals pauses downloader's transfers on SIGUSR1 and resumes
+// them on SIGUSR2, so bandwidth can be freed for a while without
+// restarting the files.
+func handlePauseSignals(downloader *gget.Client) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		for sig := range signals {
+			switch {
+			case sig == syscall.SIGUSR1 && !downloader.Paused():
+				downloader.Pause()
+				fmt.Fprintf(downloader.Log, "\nPaused; run kill -USR2 %d to resume\n", os.Getpid())
+			case sig == syscall.SIGUSR2 && downloader.Paused():
+				downloader.Unpause()
+				fmt.Fprintln(downloader.Log, "\nResuming")
+			}
+		}
+	}()
+}
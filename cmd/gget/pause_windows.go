@@ -0,0 +1,6 @@
+package main
+
+import "github.com/phx/gget"
+
+// handlePauseSignals does nothing, since Windows has no SIGUSR1 or SIGUSR2.
+func handlePauseSignals(downloader *gget.Client) {}
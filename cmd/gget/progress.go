@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// progressWriter returns where JSON progress records go for the
+// -progress and -progress-fd flags, or nil to draw the usual bar.
+func progressWriter(style string, fd int) (io.Writer, error) {
+	if fd > 0 {
+		f := os.NewFile(uintptr(fd), fmt.Sprintf("fd %d", fd))
+		if _, err := f.Stat(); err != nil {
+			return nil, fmt.Errorf("invalid -progress-fd %d: %v", fd, err)
+		}
+		return f, nil
+	}
+
+	switch style {
+	case "bar", "":
+		return nil, nil
+	case "json":
+		return os.Stderr, nil
+	}
+	return nil, fmt.Errorf("invalid -progress %q (use bar or json)", style)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/phx/gget"
+)
+
+// runPut implements `gget put`, uploading local files to Drive.
+func runPut(ctx context.Context, downloader *gget.Client, files []string, parent string, jsonOut bool) error {
+	if len(files) == 0 {
+		return fmt.Errorf("usage: gget put [-parent folder_url] <file>...")
+	}
+
+	parentID := parent
+	if parent != "" {
+		if parentID = gget.ExtractFileID(parent); parentID == "" {
+			return fmt.Errorf("could not extract folder ID from %q", parent)
+		}
+	}
+
+	var failed int
+	var first error
+	for _, file := range files {
+		info, err := downloader.Upload(ctx, file, parentID)
+		if ctx.Err() != nil {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", file, err)
+			if first == nil {
+				first = err
+			}
+			failed++
+			continue
+		}
+
+		if jsonOut {
+			data, _ := json.Marshal(info)
+			fmt.Println(string(data))
+		} else if !downloader.Quiet {
+			fmt.Printf("Uploaded %s as %s\n", file, info.ID)
+		}
+	}
+
+	if failed > 0 {
+		return &summaryError{fmt.Sprintf("%d of %d uploads failed", failed, len(files)), first}
+	}
+	return nil
+}
+
+// runPushSync implements `gget push-sync`, mirroring a local directory
+// into a Drive folder.
+func runPushSync(ctx context.Context, downloader *gget.Client, args []string, deleteExtra bool) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: gget push-sync [-delete] <directory> <folder_url>")
+	}
+
+	result, err := downloader.PushFolder(ctx, args[0], args[1], deleteExtra)
+	if !downloader.Quiet && !downloader.DryRun {
+		fmt.Printf("\nUploaded %d, updated %d, deleted %d, unchanged %d\n", result.Uploaded, result.Updated, result.Deleted, result.Unchanged)
+	}
+	return err
+}
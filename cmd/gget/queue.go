@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// QUEUE_FILE holds the state of an unfinished batch job, relative to the
+// directory it was started in.
+const QUEUE_FILE = ".gget/queue.json"
+
+// jobQueue records a batch job (a -i list, a folder or a sync) and the
+// entries it has finished, so that `gget resume` can rerun it after a
+// crash or reboot. Partial files are picked up from their .part state.
+type jobQueue struct {
+	path    string
+	Command string    `json:"command,omitempty"`
+	Args    []string  `json:"args"`
+	Done    []string  `json:"done,omitempty"`
+	Started time.Time `json:"started"`
+	Updated time.Time `json:"updated"`
+}
+
+// newQueue starts recording a job run as `gget command args...`,
+// replacing any earlier queue in the directory. Credentials are left out
+// of the recorded arguments; they are given again to gget resume.
+func newQueue(path string, command string, args []string) (*jobQueue, error) {
+	q := &jobQueue{path: path, Command: command, Args: withoutCredentials(args), Started: time.Now()}
+	if err := q.save(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// loadQueue reads the unfinished job recorded at path.
+func loadQueue(path string) (*jobQueue, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no unfinished job to resume in this directory")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job queue: %v", err)
+	}
+
+	q := &jobQueue{path: path}
+	if err := json.Unmarshal(data, q); err != nil {
+		return nil, fmt.Errorf("invalid job queue %s: %v", path, err)
+	}
+	return q, nil
+}
+
+func (q *jobQueue) isDone(entry string) bool {
+	return slices.Contains(q.Done, entry)
+}
+
+func (q *jobQueue) markDone(entry string) error {
+	q.Done = append(q.Done, entry)
+	return q.save()
+}
+
+// save writes the queue through a temporary file so that a crash never
+// leaves it half written.
+func (q *jobQueue) save() error {
+	q.Updated = time.Now()
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return fmt.Errorf("failed to create job queue: %v", err)
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to update job queue: %v", err)
+	}
+	if err := os.Rename(tmp, q.path); err != nil {
+		return fmt.Errorf("failed to update job queue: %v", err)
+	}
+	return nil
+}
+
+// remove deletes the queue of a completed job, and its directory if
+// nothing else is in it.
+func (q *jobQueue) remove() {
+	os.Remove(q.path)
+	os.Remove(filepath.Dir(q.path))
+}
+
+// credentialHeaders are the -H headers left out of a recorded job.
+var credentialHeaders = []string{"authorization", "proxy-authorization", "cookie"}
+
+// withoutCredentials returns args without -bearer, -basic and the -H
+// headers that carry credentials, together with their values, and
+// without a -proxy URL that includes a user and password.
+func withoutCredentials(args []string) []string {
+	var kept []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !strings.HasPrefix(args[i], "-") || args[i] == "-" || args[i] == "--" {
+			kept = append(kept, args[i])
+			continue
+		}
+		switch name {
+		case "bearer", "basic":
+		case "H":
+			if !hasValue && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			header, _, _ := strings.Cut(value, ":")
+			if !slices.Contains(credentialHeaders, strings.ToLower(strings.TrimSpace(header))) {
+				kept = append(kept, "-H", value)
+			}
+			continue
+		case "proxy":
+			if !hasValue && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			// A proxy login is given again to resume with the whole URL,
+			// which must not be overridden by a recorded one
+			if u, err := url.Parse(value); err != nil || u.User == nil {
+				kept = append(kept, "-proxy", value)
+			}
+			continue
+		default:
+			kept = append(kept, args[i])
+			continue
+		}
+		if !hasValue {
+			i++
+		}
+	}
+	return kept
+}
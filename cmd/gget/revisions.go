@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/phx/gget"
+)
+
+// runRevisions implements `gget revisions`, listing the stored versions
+// of a file for use with -revision.
+func runRevisions(ctx context.Context, downloader *gget.Client, args []string, jsonOut bool) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: gget revisions <google_drive_url>")
+	}
+
+	revisions, err := downloader.ListRevisions(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		for _, rev := range revisions {
+			data, _ := json.Marshal(rev)
+			fmt.Println(string(data))
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tMODIFIED\tSIZE\tMODIFIED BY")
+	for _, rev := range revisions {
+		modified := "-"
+		if !rev.Modified.IsZero() {
+			modified = rev.Modified.Local().Format(time.DateTime)
+		}
+		size := "-"
+		if rev.Size >= 0 {
+			size = gget.FormatSize(rev.Size)
+		}
+		by := rev.ModifiedBy
+		if by == "" {
+			by = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", rev.ID, modified, size, by)
+	}
+	return w.Flush()
+}
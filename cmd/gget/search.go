@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/phx/gget"
+)
+
+// runSearch implements `gget search`, listing the files matching a Drive
+// query and, with -download, downloading them.
+func runSearch(ctx context.Context, downloader *gget.Client, args []string, download, jsonOut bool) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: gget search [-download] \"<query>\"")
+	}
+
+	items, err := downloader.Search(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		for _, item := range items {
+			data, _ := json.Marshal(item)
+			fmt.Println(string(data))
+		}
+	} else if !download || !downloader.Quiet {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, item := range items {
+			size, modified, name := "-", "-", item.Name
+			if item.Size >= 0 && !item.IsFolder() {
+				size = gget.FormatSize(item.Size)
+			}
+			if !item.Modified.IsZero() {
+				modified = item.Modified.Local().Format("2006-01-02 15:04")
+			}
+			if item.IsFolder() {
+				name += "/"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.ID, size, modified, name)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("no files match %q", args[0])
+	}
+	if !download {
+		return nil
+	}
+
+	var failed int
+	var first error
+	for _, item := range items {
+		err := downloader.Download(ctx, item.URL(), gget.Options{})
+		if ctx.Err() != nil {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", item.Name, err)
+			if first == nil {
+				first = err
+			}
+			failed++
+		}
+	}
+	if failed > 0 {
+		return &summaryError{fmt.Sprintf("%d of %d downloads failed", failed, len(items)), first}
+	}
+	return nil
+}
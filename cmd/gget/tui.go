@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/phx/gget"
+)
+
+const (
+	TUI_INTERVAL     = 200 * time.Millisecond
+	TUI_NAME_WIDTH   = 32
+	TUI_BAR_WIDTH    = 20
+	TUI_FINISHED     = 10 // finished files kept on screen
+	TUI_LOG_LINES    = 5
+	TUI_CLEAR_SCREEN = "\x1b[H\x1b[2J"
+)
+
+// tuiRow is one file on the screen.
+type tuiRow struct {
+	name  string
+	url   string
+	id    string
+	state string // active, done, skipped or failed
+	bytes int64
+	size  int64
+	speed int64
+	err   string
+}
+
+// tui draws a live table of a batch or folder download on the terminal's
+// alternate screen: a header with entry counts, a row per active file
+// with its progress bar and speed, the most recent finished and failed
+// files, and the last status messages. It is fed by the client's Events
+// and Log, and by the batch loop through begin and end.
+type tui struct {
+	mu      sync.Mutex
+	out     io.Writer
+	started time.Time
+	rows    []*tuiRow
+	byName  map[string]*tuiRow
+	log     []string
+	partial string
+
+	queued, active, done, failed int
+
+	stopped chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newTUI takes over out, a terminal, until stop is called. queued is the
+// number of entries waiting, or -1 when they are still being read.
+func newTUI(out io.Writer, queued int) *tui {
+	t := &tui{out: out, started: time.Now(), byName: make(map[string]*tuiRow), queued: queued, stopped: make(chan struct{})}
+	// Alternate screen, cursor hidden
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(TUI_INTERVAL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.draw()
+			case <-t.stopped:
+				return
+			}
+		}
+	}()
+	return t
+}
+
+// stop restores the terminal and prints the files that failed, which
+// would otherwise vanish with the alternate screen.
+func (t *tui) stop() {
+	close(t.stopped)
+	t.wg.Wait()
+	fmt.Fprint(t.out, "\x1b[?25h\x1b[?1049l")
+	for _, row := range t.rows {
+		if row.state == "failed" {
+			fmt.Fprintf(t.out, "Error: %s: %s\n", row.name, row.err)
+		}
+	}
+}
+
+// begin and end bracket the download of one batch entry.
+func (t *tui) begin() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.queued > 0 {
+		t.queued--
+	}
+	t.active++
+}
+
+func (t *tui) end(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active--
+	if err != nil {
+		t.failed++
+	} else {
+		t.done++
+	}
+}
+
+// events returns the writer for the client's Events.
+func (t *tui) events() io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		for _, line := range bytes.Split(bytes.TrimSpace(p), []byte("\n")) {
+			var event gget.Event
+			if json.Unmarshal(line, &event) == nil {
+				t.record(event)
+			}
+		}
+		return len(p), nil
+	})
+}
+
+// logger returns the writer for the client's Log, whose lines are shown
+// below the table. Progress lines ending in \r are dropped.
+func (t *tui) logger() io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		lines := strings.Split(t.partial+string(p), "\n")
+		t.partial = lines[len(lines)-1]
+		for _, line := range lines[:len(lines)-1] {
+			if line = strings.TrimSpace(line[strings.LastIndex(line, "\r")+1:]); line != "" {
+				t.log = append(t.log, line)
+			}
+		}
+		if len(t.log) > TUI_LOG_LINES {
+			t.log = t.log[len(t.log)-TUI_LOG_LINES:]
+		}
+		return len(p), nil
+	})
+}
+
+func (t *tui) record(event gget.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if event.Type == "error" && event.Name == "" {
+		// A failed entry: fail the files it left active, or show it
+		// as a row of its own
+		matched := false
+		for _, row := range t.rows {
+			if row.state == "active" && ((event.ID != "" && row.id == event.ID) || row.url == event.URL) {
+				row.state, row.err, matched = "failed", event.Error, true
+			}
+		}
+		if !matched {
+			t.rows = append(t.rows, &tuiRow{name: event.URL, url: event.URL, state: "failed", err: event.Error})
+		}
+		return
+	}
+	if event.Name == "" {
+		return
+	}
+
+	row := t.byName[event.Name]
+	if row == nil {
+		row = &tuiRow{name: event.Name}
+		t.byName[event.Name] = row
+		t.rows = append(t.rows, row)
+	}
+	row.url, row.id = event.URL, event.ID
+	switch event.Type {
+	case "start":
+		row.state, row.size, row.bytes, row.err = "active", event.Size, 0, ""
+	case "progress":
+		row.state, row.bytes, row.speed = "active", event.Bytes, event.Speed
+		if event.Size > 0 {
+			row.size = event.Size
+		}
+	case "done":
+		row.state, row.bytes = "done", event.Size
+		if event.Duration > 0 {
+			row.speed = int64(float64(event.Size) / event.Duration)
+		}
+	case "skip":
+		row.state = "skipped"
+	case "error":
+		row.state, row.err = "failed", event.Error
+	}
+}
+
+func (t *tui) draw() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString(TUI_CLEAR_SCREEN)
+	queued := "-"
+	if t.queued >= 0 {
+		queued = fmt.Sprint(t.queued)
+	}
+	fmt.Fprintf(&b, "gget  %v  queued %s  active %d  done %d  failed %d\n\n",
+		time.Since(t.started).Round(time.Second), queued, t.active, t.done, t.failed)
+
+	var finished []*tuiRow
+	for _, row := range t.rows {
+		if row.state == "active" {
+			b.WriteString(row.line() + "\n")
+		} else {
+			finished = append(finished, row)
+		}
+	}
+	if len(finished) > TUI_FINISHED {
+		finished = finished[len(finished)-TUI_FINISHED:]
+	}
+	for _, row := range finished {
+		b.WriteString(row.line() + "\n")
+	}
+
+	if len(t.log) > 0 {
+		b.WriteString("\n")
+		for _, line := range t.log {
+			b.WriteString(line + "\n")
+		}
+	}
+	io.WriteString(t.out, b.String())
+}
+
+// line formats the row as name, bar, sizes, speed and state.
+func (r *tuiRow) line() string {
+	name := []rune(r.name)
+	if len(name) > TUI_NAME_WIDTH {
+		name = append([]rune("..."), name[len(name)-TUI_NAME_WIDTH+3:]...)
+	}
+
+	bar, sizes := strings.Repeat(" ", TUI_BAR_WIDTH), gget.FormatSize(r.bytes)
+	if r.size > 0 {
+		filled := int(min(float64(r.bytes)/float64(r.size), 1) * TUI_BAR_WIDTH)
+		if r.state == "done" {
+			filled = TUI_BAR_WIDTH
+		}
+		bar = strings.Repeat("=", filled) + strings.Repeat(" ", TUI_BAR_WIDTH-filled)
+		sizes += " / " + gget.FormatSize(r.size)
+	}
+
+	state := r.state
+	if r.err != "" {
+		state += ": " + r.err
+	}
+	return fmt.Sprintf("%-*s [%s] %-23s %10s/s  %s", TUI_NAME_WIDTH, string(name), bar, sizes, gget.FormatSize(r.speed), state)
+}
+
+// writerFunc adapts a function to io.Writer.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+// canUseTUI reports whether -tui can draw on standard output.
+func canUseTUI() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0 && os.Getenv("TERM") != "dumb"
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/phx/gget"
+)
+
+// runVerify implements `gget verify`, comparing a downloaded folder with
+// its remote copy without downloading anything.
+func runVerify(ctx context.Context, downloader *gget.Client, args []string, jsonOut bool) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: gget verify <folder_url> <directory>")
+	}
+
+	checks, err := downloader.VerifyFolder(ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, check := range checks {
+		counts[check.Status]++
+		switch {
+		case jsonOut:
+			data, _ := json.Marshal(check)
+			fmt.Println(string(data))
+		case check.Status == "ok":
+		case check.Detail != "":
+			fmt.Printf("%-8s %s (%s)\n", check.Status, check.Path, check.Detail)
+		default:
+			fmt.Printf("%-8s %s\n", check.Status, check.Path)
+		}
+	}
+
+	if !jsonOut {
+		fmt.Printf("\n%d ok, %d missing, %d changed, %d extra\n", counts["ok"], counts["missing"], counts["changed"], counts["extra"])
+	}
+	if differ := len(checks) - counts["ok"]; differ > 0 {
+		return &summaryError{fmt.Sprintf("%d files differ from the remote folder", differ), gget.ErrChecksumMismatch}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package gget
+
+import (
+	"context"
+	"fmt"
+)
+
+// confirmedKey marks a context whose downloads were already approved as a
+// whole, such as the files of a confirmed folder.
+type confirmedKey struct{}
+
+func withConfirmed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, confirmedKey{}, true)
+}
+
+// confirmSize asks Confirm before a download of size bytes to name when
+// that is above ConfirmSize, and fails if it is declined.
+func (g *Client) confirmSize(ctx context.Context, name string, size int64) error {
+	if g.ConfirmSize <= 0 || size <= g.ConfirmSize || ctx.Value(confirmedKey{}) != nil {
+		return nil
+	}
+	if g.Confirm == nil || !g.Confirm(name, size) {
+		return fmt.Errorf("download of %s (%s) not confirmed: it is larger than %s", name, FormatSize(size), FormatSize(g.ConfirmSize))
+	}
+	return nil
+}
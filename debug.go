@@ -0,0 +1,128 @@
+package gget
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requestTrace prints the phases of one request to Log for Debug: DNS,
+// connect, TLS handshake and time to first byte. Redirects reuse the
+// trace, so each hop starts its clock again in GetConn.
+type requestTrace struct {
+	g     *Client
+	mu    sync.Mutex
+	start time.Time // of the current hop
+	dns   time.Time
+	conn  time.Time
+	tls   time.Time
+}
+
+func (t *requestTrace) printf(format string, args ...interface{}) {
+	fmt.Fprintf(t.g.Log, "* "+format+"\n", args...)
+}
+
+// elapsed is the time from mark to now, as it is printed.
+func elapsed(mark time.Time) time.Duration {
+	return time.Since(mark).Round(10 * time.Microsecond)
+}
+
+func (t *requestTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.start = time.Now()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				t.printf("Reusing connection to %s (idle %v)", info.Conn.RemoteAddr(), info.IdleTime.Round(time.Millisecond))
+			}
+		},
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dns = time.Now()
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if info.Err != nil {
+				t.printf("DNS lookup failed after %v: %v", elapsed(t.dns), info.Err)
+				return
+			}
+			var addrs []string
+			for _, addr := range info.Addrs {
+				addrs = append(addrs, addr.String())
+			}
+			t.printf("DNS lookup took %v: %s", elapsed(t.dns), strings.Join(addrs, ", "))
+		},
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.conn = time.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err != nil {
+				t.printf("Connecting to %s failed after %v: %v", addr, elapsed(t.conn), err)
+				return
+			}
+			t.printf("Connected to %s in %v", addr, elapsed(t.conn))
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tls = time.Now()
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err != nil {
+				t.printf("TLS handshake failed after %v: %v", elapsed(t.tls), err)
+				return
+			}
+			t.printf("TLS handshake (%s, %s) took %v", tls.VersionName(state.Version), state.NegotiatedProtocol, elapsed(t.tls))
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.printf("First response byte after %v", elapsed(t.start))
+		},
+	}
+}
+
+// traceRequest returns req set up to print its timings when Debug is set.
+func (g *Client) traceRequest(req *http.Request) *http.Request {
+	if !g.Debug {
+		return req
+	}
+	fmt.Fprintf(g.Log, "* %s %s\n", req.Method, req.URL.Redacted())
+	t := &requestTrace{g: g, start: time.Now()}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), t.clientTrace()))
+}
+
+// checkRedirect follows every redirect, printing each hop with Debug.
+func (g *Client) checkRedirect(req *http.Request, via []*http.Request) error {
+	if g.Debug && req.Response != nil {
+		fmt.Fprintf(g.Log, "* %s, redirected to %s\n", req.Response.Status, req.URL.Redacted())
+	}
+	return nil
+}
+
+// debugResult prints, with Debug, how a request made at started ended.
+func (g *Client) debugResult(resp *http.Response, err error, started time.Time) {
+	if !g.Debug {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(g.Log, "* Failed after %v: %v\n", elapsed(started), err)
+		return
+	}
+	fmt.Fprintf(g.Log, "* %s after %v (Content-Length %d)\n", resp.Status, elapsed(started), resp.ContentLength)
+}
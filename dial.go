@@ -0,0 +1,52 @@
+package gget
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// dialContext opens connections for the transport, applying the address
+// family chosen with SetIPVersion and any AddResolve overrides.
+func (g *Client) dialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
+	switch g.ipVersion {
+	case 4:
+		network = "tcp4"
+	case 6:
+		network = "tcp6"
+	}
+	if override, ok := g.resolve[strings.ToLower(addr)]; ok {
+		addr = override
+	}
+	return g.dialer.DialContext(ctx, network, addr)
+}
+
+// SetIPVersion restricts connections to IPv4 (4) or IPv6 (6) addresses;
+// zero allows both.
+func (g *Client) SetIPVersion(version int) error {
+	switch version {
+	case 0, 4, 6:
+		g.ipVersion = version
+		return nil
+	}
+	return fmt.Errorf("invalid IP version %d", version)
+}
+
+// AddResolve connects to addr whenever host:port is requested, given as
+// curl's "host:port:addr", e.g. "example.com:443:127.0.0.1" or
+// "example.com:443:[::1]".
+func (g *Client) AddResolve(spec string) error {
+	host, rest, ok1 := strings.Cut(spec, ":")
+	port, addr, ok2 := strings.Cut(rest, ":")
+	addr = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+	if !ok1 || !ok2 || host == "" || port == "" || net.ParseIP(addr) == nil {
+		return fmt.Errorf("invalid resolve entry %q (want host:port:address)", spec)
+	}
+
+	if g.resolve == nil {
+		g.resolve = make(map[string]string)
+	}
+	g.resolve[strings.ToLower(net.JoinHostPort(host, port))] = net.JoinHostPort(addr, port)
+	return nil
+}
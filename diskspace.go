@@ -0,0 +1,38 @@
+package gget
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// checkSpace fails with ErrDisk when the filesystem holding path has less
+// than need bytes free. Platforms that cannot report free space pass.
+func (g *Client) checkSpace(path string, need int64) error {
+	if !g.SpaceCheck || need <= 0 {
+		return nil
+	}
+
+	dir := existingDir(path)
+	free, ok := freeSpace(dir)
+	if !ok || free >= need {
+		return nil
+	}
+	return classify(ErrDisk, fmt.Errorf("not enough disk space in %s: need %s, %s free", dir, FormatSize(need), FormatSize(free)))
+}
+
+// existingDir returns the nearest directory at or above path that exists,
+// since the output directory may not have been created yet.
+func existingDir(path string) string {
+	dir := filepath.Clean(path)
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,73 @@
+package gget
+
+import (
+	"mime"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	extFilenameRe = regexp.MustCompile(`(?i)(?:^|;)\s*filename\*\s*=\s*([^;]*)`)
+	filenameRe    = regexp.MustCompile(`(?i)(?:^|;)\s*filename\s*=\s*("(?:[^"\\]|\\.)*"|[^;]*)`)
+)
+
+// dispositionFilename extracts the file name from a Content-Disposition
+// header following RFC 6266: the RFC 5987 filename* form, with its charset
+// and percent-encoding, is preferred over the plain filename parameter.
+func dispositionFilename(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	if matches := extFilenameRe.FindStringSubmatch(header); matches != nil {
+		if name, ok := decodeExtValue(strings.TrimSpace(matches[1])); ok && name != "" {
+			return name
+		}
+	}
+
+	if _, params, err := mime.ParseMediaType(header); err == nil && params["filename"] != "" {
+		return params["filename"]
+	}
+
+	// Servers often send headers the strict parser rejects, such as
+	// unquoted names containing spaces
+	if matches := filenameRe.FindStringSubmatch(header); matches != nil {
+		value := strings.TrimSpace(matches[1])
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(value[1 : len(value)-1])
+		}
+		return value
+	}
+	return ""
+}
+
+// decodeExtValue decodes an RFC 5987 ext-value: charset'language'value
+// with percent-encoded octets. UTF-8 and ISO-8859-1 are supported.
+func decodeExtValue(value string) (string, bool) {
+	charset, rest, ok := strings.Cut(strings.Trim(value, `"`), "'")
+	if !ok {
+		return "", false
+	}
+	_, encoded, ok := strings.Cut(rest, "'")
+	if !ok {
+		return "", false
+	}
+
+	decoded, err := url.PathUnescape(encoded)
+	if err != nil {
+		return "", false
+	}
+
+	switch strings.ToLower(charset) {
+	case "utf-8", "us-ascii", "":
+		return decoded, true
+	case "iso-8859-1", "latin1":
+		runes := make([]rune, len(decoded))
+		for i := 0; i < len(decoded); i++ {
+			runes[i] = rune(decoded[i])
+		}
+		return string(runes), true
+	}
+	return "", false
+}
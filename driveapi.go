@@ -0,0 +1,207 @@
+package gget
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const DRIVE_API_URL = "https://www.googleapis.com/drive/v3"
+
+// driveFile holds the Drive API file metadata gget uses.
+type driveFile struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	MimeType string `json:"mimeType"`
+	Size     string `json:"size"`
+	MD5      string `json:"md5Checksum"`
+	Modified string `json:"modifiedTime"`
+	Created  string `json:"createdTime"`
+	Trashed  bool   `json:"trashed"`
+	// Description is the text set in the file's details pane
+	Description string `json:"description"`
+	Owners      []struct {
+		DisplayName  string `json:"displayName"`
+		EmailAddress string `json:"emailAddress"`
+	} `json:"owners"`
+	ShortcutDetails struct {
+		TargetID       string `json:"targetId"`
+		TargetMimeType string `json:"targetMimeType"`
+	} `json:"shortcutDetails"`
+}
+
+const driveFileFields = "id,name,mimeType,size,md5Checksum,modifiedTime,createdTime,trashed,description,owners(displayName,emailAddress),shortcutDetails(targetId,targetMimeType)"
+
+const SHORTCUT_MIME_TYPE = "application/vnd.google-apps.shortcut"
+
+func apiURL(path string, query url.Values) string {
+	return DRIVE_API_URL + path + "?" + query.Encode()
+}
+
+// allDrives opts a Drive API request into items in Shared Drives, which
+// are otherwise reported as not found. Listings (those with a q parameter)
+// also need includeItemsFromAllDrives.
+func allDrives(query url.Values) url.Values {
+	query.Set("supportsAllDrives", "true")
+	if query.Has("q") {
+		query.Set("includeItemsFromAllDrives", "true")
+	}
+	return query
+}
+
+// mediaURL is the Drive API endpoint serving the content of fileID.
+func mediaURL(fileID string) string {
+	return apiURL("/files/"+url.PathEscape(fileID), allDrives(url.Values{"alt": {"media"}}))
+}
+
+// trashedError explains why a file in the trash is not downloaded.
+func trashedError(name string) error {
+	return classify(ErrPermissionDenied, fmt.Errorf("%s is in the owner's trash; restore it to download it, or pass -include-trashed if you can still read it", name))
+}
+
+// apiError turns a failed Drive API response into an error carrying the
+// API's own message.
+func apiError(resp *http.Response) error {
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+			Errors  []struct {
+				Reason string `json:"reason"`
+			} `json:"errors"`
+		} `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if json.Unmarshal(data, &body) == nil && body.Error.Message != "" {
+		for _, e := range body.Error.Errors {
+			if e.Reason == "downloadQuotaExceeded" {
+				return ErrQuotaExceeded
+			}
+		}
+		return statusError(resp.StatusCode, fmt.Errorf("drive API error (%s): %s", resp.Status, body.Error.Message))
+	}
+	return statusError(resp.StatusCode, fmt.Errorf("drive API error: %s", resp.Status))
+}
+
+// getJSON performs an authenticated Drive API GET and decodes the result.
+func (g *Client) getJSON(ctx context.Context, urlStr string, v interface{}) error {
+	req, err := g.newRequest(ctx, urlStr)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := g.do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return apiError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid API response: %v", err)
+	}
+	return nil
+}
+
+// sendJSON performs an authenticated Drive API request with body encoded
+// as JSON, decoding the result into v unless it is nil.
+func (g *Client) sendJSON(ctx context.Context, method string, urlStr string, body interface{}, v interface{}) error {
+	data, _ := json.Marshal(body)
+	req, err := g.newAPIRequest(ctx, method, urlStr, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	resp, err := g.do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return writeError(resp)
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid API response: %v", err)
+	}
+	return nil
+}
+
+func (g *Client) getMetadata(ctx context.Context, fileID string) (*driveFile, error) {
+	var file driveFile
+	err := g.getJSON(ctx, apiURL("/files/"+url.PathEscape(fileID), allDrives(url.Values{"fields": {driveFileFields}})), &file)
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// resolveShortcut returns the metadata of the file a shortcut points to,
+// or meta itself for other files and when KeepShortcuts is set.
+func (g *Client) resolveShortcut(ctx context.Context, meta *driveFile) (*driveFile, error) {
+	if meta.MimeType != SHORTCUT_MIME_TYPE || g.KeepShortcuts {
+		return meta, nil
+	}
+	target, err := g.getMetadata(ctx, meta.ShortcutDetails.TargetID)
+	if err != nil {
+		return nil, fmt.Errorf("shortcut %s: %w", meta.Name, err)
+	}
+	return target, nil
+}
+
+// downloadFromAPI fetches fileID through the Drive API's alt=media
+// endpoint, which serves any file the authenticated account can read
+// without the HTML confirmation page.
+func (g *Client) downloadFromAPI(ctx context.Context, fileID string, output string) error {
+	meta, err := g.getMetadata(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	if meta, err = g.resolveShortcut(ctx, meta); err != nil {
+		return err
+	}
+	switch {
+	case meta.MimeType == SHORTCUT_MIME_TYPE:
+		return fmt.Errorf("%s is a shortcut, which has no content of its own", meta.Name)
+	case meta.MimeType == FOLDER_MIME_TYPE:
+		return fmt.Errorf("%s is a folder; use its folder URL to download it", meta.Name)
+	}
+	if meta.Trashed && !g.IncludeTrashed {
+		return trashedError(meta.Name)
+	}
+	fileID = meta.ID
+
+	if kind := nativeKinds[meta.MimeType]; kind != "" {
+		return g.exportFromAPI(ctx, kind, meta, output)
+	}
+
+	req, err := g.newRequest(ctx, mediaURL(fileID))
+	if err != nil {
+		return fmt.Errorf("failed to create download request: %v", err)
+	}
+
+	resp, err := g.do(req)
+	if err != nil {
+		return fmt.Errorf("download request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return apiError(resp)
+	}
+
+	if output == "" {
+		output = g.placeOutput(ctx, meta.Name, fileID, meta.MimeType)
+	}
+
+	return g.saveResponse(ctx, resp, &transfer{output: output, md5: meta.MD5, id: fileID, modified: meta.info().Modified, meta: meta})
+}
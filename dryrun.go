@@ -0,0 +1,27 @@
+package gget
+
+import "fmt"
+
+// printDryRun reports a file that would have been downloaded to t.output
+// and adds it to the totals returned by DryRunTotal.
+func (g *Client) printDryRun(t *transfer, size int64) {
+	source := t.url
+	if source == "" {
+		source = t.id
+	}
+
+	sizeStr := "unknown size"
+	if size >= 0 {
+		sizeStr = FormatSize(size)
+		g.dryRunBytes.Add(size)
+	}
+	g.dryRunFiles.Add(1)
+
+	fmt.Fprintf(g.Log, "Would download %s (%s) from %s\n", t.output, sizeStr, source)
+}
+
+// DryRunTotal returns the number of files and known bytes that DryRun
+// downloads would have fetched so far.
+func (g *Client) DryRunTotal() (files int64, bytes int64) {
+	return g.dryRunFiles.Load(), g.dryRunBytes.Load()
+}
@@ -0,0 +1,47 @@
+package gget
+
+import "errors"
+
+// Error classes. Errors returned by Download and the other Client methods
+// match at most one of these (or ErrQuotaExceeded) with errors.Is, so
+// callers can tell failures apart without parsing messages.
+var (
+	ErrInvalidURL       = errors.New("invalid URL")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrNetwork          = errors.New("network failure")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrDisk             = errors.New("disk error")
+)
+
+var errNoFileID = classify(ErrInvalidURL, errors.New("could not extract file ID from URL"))
+
+// errNeedsAuth is returned by features only the Drive API offers.
+var errNeedsAuth = errors.New("this needs the Drive API; sign in with `gget auth login` or use a service account")
+
+// classError tags err with a class while keeping its message.
+type classError struct {
+	class error
+	err   error
+}
+
+func (e *classError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classError) Unwrap() []error {
+	return []error{e.class, e.err}
+}
+
+func classify(class error, err error) error {
+	return &classError{class, err}
+}
+
+// statusError classifies a failed HTTP status: 401, 403 and 404 mean the
+// file is private or gone, which Drive does not distinguish.
+func statusError(status int, err error) error {
+	switch status {
+	case 401, 403, 404:
+		return classify(ErrPermissionDenied, err)
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package gget
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// Event is a machine-readable record of download activity. When
+// Client.Events is set, each event is written to it as one line of JSON.
+type Event struct {
+	Type     string  `json:"type"` // start, progress, done, skip, error or summary
+	URL      string  `json:"url,omitempty"`
+	ID       string  `json:"id,omitempty"`
+	Name     string  `json:"name,omitempty"`
+	Size     int64   `json:"size,omitempty"`
+	Bytes    int64   `json:"bytes,omitempty"`
+	Speed    int64   `json:"speed,omitempty"`    // bytes per second
+	MD5      string  `json:"md5,omitempty"`      // hex checksum of the finished file
+	Duration float64 `json:"duration,omitempty"` // seconds since the transfer started
+	Error    string  `json:"error,omitempty"`
+	Stats    *Stats  `json:"stats,omitempty"` // totals of the run, in summary events
+}
+
+// optionsKey carries the Options of a Download call in its context, so
+// that each transfer it starts can find the callbacks and byte range.
+type optionsKey struct{}
+
+func (g *Client) emit(event Event) {
+	g.stats.count(event.Type)
+	if g.Events == nil {
+		return
+	}
+	data, _ := json.Marshal(event)
+	g.eventsMu.Lock()
+	defer g.eventsMu.Unlock()
+	g.Events.Write(append(data, '\n'))
+}
+
+// emitStart reports that the body of t is about to be written.
+func (g *Client) emitStart(t *transfer, size int64) {
+	t.started = time.Now()
+	g.emit(Event{Type: "start", URL: t.url, ID: t.id, Name: t.output, Size: max(size, 0)})
+}
+
+// emitDone reports a finished file to Events and OnDone, hashing it if no
+// checksum is known.
+func (g *Client) emitDone(t *transfer) {
+	if g.Events == nil && t.onDone == nil {
+		g.stats.count("done")
+		return
+	}
+	event := Event{Type: "done", URL: t.url, ID: t.id, Name: t.output, MD5: t.md5}
+	if info, err := os.Stat(t.path()); err == nil {
+		event.Size = info.Size()
+	}
+	if event.MD5 == "" {
+		event.MD5, _ = fileMD5(t.path())
+	}
+	if !t.started.IsZero() {
+		event.Duration = time.Since(t.started).Seconds()
+	}
+	g.emit(event)
+	if t.onDone != nil {
+		t.onDone(event)
+	}
+}
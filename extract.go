@@ -0,0 +1,163 @@
+package gget
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archiveKind reports which supported archive format name has, if any.
+func archiveKind(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	}
+	return ""
+}
+
+// extractOutput unpacks a finished archive download next to it, removing
+// the archive afterwards with RemoveArchive. Other files are left alone.
+func (g *Client) extractOutput(t *transfer) error {
+	kind := archiveKind(t.output)
+	if kind == "" {
+		return nil
+	}
+
+	dir := filepath.Dir(t.output)
+	if !g.Quiet {
+		fmt.Fprintf(g.Log, "Extracting %s\n", t.output)
+	}
+
+	var err error
+	if kind == "zip" {
+		err = extractZip(t.path(), dir)
+	} else {
+		err = extractTar(t.path(), dir, kind == "tar.gz")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %v", t.output, err)
+	}
+
+	if g.RemoveArchive {
+		if err := os.Remove(t.path()); err != nil {
+			return fmt.Errorf("failed to remove archive: %v", err)
+		}
+	}
+	return nil
+}
+
+// archivePath maps an archive member to its path under dir, refusing
+// absolute paths and ".." components that would write outside of dir
+// ("zip slip").
+func archivePath(dir string, name string) (string, error) {
+	name = filepath.FromSlash(strings.TrimPrefix(name, "./"))
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("unsafe path %q in archive", name)
+	}
+	return longPath(filepath.Join(dir, name)), nil
+}
+
+func extractZip(archive string, dir string) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		target, err := archivePath(dir, f.Name)
+		if err != nil {
+			return err
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.Mode().IsRegular() {
+			continue
+		}
+
+		src, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writeMember(target, src, f.Mode())
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTar(archive string, dir string, gzipped bool) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if gzipped {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := archivePath(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeMember(target, tr, header.FileInfo().Mode()); err != nil {
+				return err
+			}
+		}
+		// Links and special files are skipped: they could point outside dir
+	}
+}
+
+func writeMember(target string, src io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,70 @@
+package gget
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// SetFilters limits folder downloads to files whose remote path matches
+// one of the include globs (all files when there are none) and none of
+// the exclude globs. Patterns without a slash match the file name in any
+// directory; others match the whole path, where ** spans any number of
+// directories, e.g. "*.csv" or "raw/**".
+func (g *Client) SetFilters(include []string, exclude []string) error {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+	g.include, g.exclude = include, exclude
+	return nil
+}
+
+// wanted reports whether a file at the remote path p passes the filters.
+func (g *Client) wanted(p string) bool {
+	if len(g.include) > 0 && !matchAny(g.include, p) {
+		return false
+	}
+	return !matchAny(g.exclude, p)
+}
+
+func matchAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchGlob(pattern string, p string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(p))
+		return ok
+	}
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(p, "/"))
+}
+
+// matchSegments matches path segments against pattern segments, with **
+// standing for zero or more segments.
+func matchSegments(pattern []string, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(segments); i >= 0; i-- {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
@@ -0,0 +1,302 @@
+package gget
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const FOLDER_MIME_TYPE = "application/vnd.google-apps.folder"
+
+// ListFolder lists the items of a Drive folder, descending into subfolders
+// when recursive is set. Each item's Path is relative to the folder. The
+// Drive API is used when authenticated; public folders are otherwise read
+// from their embedded view, which does not report sizes or types. Items in
+// the trash are left out unless IncludeTrashed is set.
+func (g *Client) ListFolder(ctx context.Context, urlStr string, recursive bool) ([]*FileInfo, error) {
+	folderID := ExtractFileID(urlStr)
+	if folderID == "" {
+		return nil, classify(ErrInvalidURL, fmt.Errorf("could not extract folder ID from URL"))
+	}
+	g.rememberResourceKey(folderID, urlStr)
+
+	return g.listFolder(ctx, folderID, "", recursive, !g.KeepShortcuts, map[string]bool{folderID: true})
+}
+
+// listFolder lists folderID under prefix, listing the targets of shortcuts
+// in their place when follow is set. Folders already in seen are not
+// entered again, since shortcuts can point back up the tree.
+func (g *Client) listFolder(ctx context.Context, folderID string, prefix string, recursive bool, follow bool, seen map[string]bool) ([]*FileInfo, error) {
+	var items []*FileInfo
+	var err error
+	if g.Auth != nil {
+		items, err = g.listFromAPI(ctx, folderID, follow)
+	} else {
+		items, err = g.listEmbedded(ctx, folderID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var all []*FileInfo
+	for _, item := range items {
+		item.Path, item.rawPath = path.Join(prefix, g.sanitizeName(item.Name)), item.Name
+		all = append(all, item)
+
+		if recursive && item.IsFolder() && !seen[item.ID] {
+			seen[item.ID] = true
+			children, err := g.listFolder(ctx, item.ID, item.Path, true, follow, seen)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", item.Path, err)
+			}
+			for _, child := range children {
+				child.rawPath = path.Join(item.Name, child.rawPath)
+			}
+			all = append(all, children...)
+		}
+	}
+	return all, nil
+}
+
+// listFromAPI returns the Drive API children of folderID.
+func (g *Client) listFromAPI(ctx context.Context, folderID string, follow bool) ([]*FileInfo, error) {
+	q := fmt.Sprintf("'%s' in parents", folderID)
+	if !g.IncludeTrashed {
+		q += " and trashed = false"
+	}
+	files, err := g.queryFiles(ctx, q, "folder,name")
+	if err != nil {
+		return nil, err
+	}
+	var items []*FileInfo
+	for i := range files {
+		file := &files[i]
+		if file.MimeType == SHORTCUT_MIME_TYPE && follow {
+			// List the target under the shortcut's name; its size and
+			// checksum are looked up when it is downloaded
+			file.ID, file.MimeType, file.Size, file.MD5 = file.ShortcutDetails.TargetID, file.ShortcutDetails.TargetMimeType, "", ""
+		}
+		items = append(items, file.info())
+	}
+	return items, nil
+}
+
+var (
+	embeddedEntryRe = regexp.MustCompile(`(?s)<div class="flip-entry" id="entry-([^"]+)".*?<a href="([^"]+)".*?<div class="flip-entry-title">(.*?)</div>`)
+	embeddedTitleRe = regexp.MustCompile(`(?s)<title>(.*?)</title>`)
+)
+
+// listEmbedded reads a public folder's items from its embedded view.
+func (g *Client) listEmbedded(ctx context.Context, folderID string) ([]*FileInfo, error) {
+	viewURL := g.withResourceKey("https://drive.google.com/embeddedfolderview?id="+url.QueryEscape(folderID), folderID)
+	req, err := g.newRequest(ctx, viewURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := g.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, classify(ErrPermissionDenied, fmt.Errorf("cannot list folder (%s): it may not be shared publicly", resp.Status))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, g.MaxHTMLSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read folder listing: %v", err)
+	}
+	if name := embeddedTitleRe.FindStringSubmatch(string(body)); name != nil {
+		g.folderNames.Store(folderID, html.UnescapeString(name[1]))
+	}
+
+	var items []*FileInfo
+	for _, match := range embeddedEntryRe.FindAllStringSubmatch(string(body), -1) {
+		item := &FileInfo{ID: match[1], Name: html.UnescapeString(match[3]), Size: -1}
+		if strings.Contains(match[2], "/folders/") {
+			item.MimeType = FOLDER_MIME_TYPE
+		} else if kind := documentKind(match[2]); kind != "" {
+			for mimeType, k := range nativeKinds {
+				if k == kind {
+					item.MimeType = mimeType
+				}
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+var folderURLRe = regexp.MustCompile(`/folders/[^/?#]+`)
+
+// IsFolderURL reports whether urlStr links to a Drive folder.
+func IsFolderURL(urlStr string) bool {
+	return folderURLRe.MatchString(urlStr)
+}
+
+// URL is the Drive URL gget downloads the item from.
+func (f *FileInfo) URL() string {
+	if f.IsFolder() {
+		return fmt.Sprintf("https://drive.google.com/drive/folders/%s", f.ID)
+	}
+	if kind := nativeKinds[f.MimeType]; kind == "script" {
+		return fmt.Sprintf("https://script.google.com/d/%s/edit", f.ID)
+	} else if kind != "" {
+		return fmt.Sprintf("https://docs.google.com/%s/d/%s/edit", kind, f.ID)
+	}
+	return fmt.Sprintf("https://drive.google.com/file/d/%s/view", f.ID)
+}
+
+// folderName looks up the name of folderID, falling back to the ID.
+func (g *Client) folderName(ctx context.Context, folderID string) string {
+	if g.Auth != nil {
+		if meta, err := g.getMetadata(ctx, folderID); err == nil {
+			return meta.Name
+		}
+	} else if name, ok := g.folderNames.Load(folderID); ok {
+		return name.(string)
+	}
+	return folderID
+}
+
+// folderKey marks the context of the files of a folder download.
+type folderKey struct{}
+
+// skipExisting reports whether a download made with ctx skips a local copy
+// that is already complete: with SkipExisting, or with FolderResume for
+// the files of a folder.
+func (g *Client) skipExisting(ctx context.Context) bool {
+	return g.SkipExisting || (g.FolderResume && ctx.Value(folderKey{}) != nil)
+}
+
+// resume reports whether a download made with ctx continues an existing
+// .part file, as skipExisting does for Resume.
+func (g *Client) resume(ctx context.Context) bool {
+	return g.Resume || (g.FolderResume && ctx.Value(folderKey{}) != nil)
+}
+
+// downloadFolder downloads every file in a folder and its subfolders into
+// output, or into a directory named after the folder under g.Directory,
+// keeping the folder structure. Failed files are reported and skipped, and
+// files excluded by SetFilters are left out. With FolderResume, rerunning
+// an interrupted folder download only fetches what is still missing.
+func (g *Client) downloadFolder(ctx context.Context, urlStr string, opts Options) error {
+	items, err := g.ListFolder(ctx, urlStr, true)
+	if err != nil {
+		return err
+	}
+
+	dir := opts.Output
+	if dir == "" {
+		dir = filepath.Join(g.Directory, g.sanitizeName(g.folderName(ctx, ExtractFileID(urlStr))))
+	}
+
+	var files []*FileInfo
+	for _, item := range items {
+		if !item.IsFolder() && item.MimeType != SHORTCUT_MIME_TYPE && g.wanted(item.Path) {
+			files = append(files, item)
+		}
+	}
+
+	if !opts.SkipDownload && !g.DryRun {
+		var total, need int64
+		for i, item := range files {
+			total += max(item.Size, 0)
+			output, err := g.folderOutput(dir, item, i+1)
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(output); err != nil {
+				need += max(item.Size, 0)
+			}
+		}
+		if err := g.confirmSize(ctx, fmt.Sprintf("%s (%d files)", dir, len(files)), total); err != nil {
+			return err
+		}
+		ctx = withConfirmed(ctx)
+		if err := g.checkSpace(dir, need); err != nil {
+			return err
+		}
+	}
+
+	if g.FolderZip && !opts.SkipDownload && !g.DryRun {
+		return g.downloadFolderZip(ctx, files, withExtension(dir, "zip"))
+	}
+	ctx = context.WithValue(ctx, folderKey{}, true)
+
+	var failed int
+	var fetched bool
+	for i, item := range files {
+		output, err := g.folderOutput(dir, item, i+1)
+		if err != nil {
+			return err
+		}
+
+		if opts.SkipDownload {
+			err = g.printURL(ctx, g.resolverFor(item.URL(), false), item.URL())
+		} else if g.skipExisting(ctx) && item.Size >= 0 && upToDate(&transfer{output: output, md5: item.MD5}, item.Size) {
+			// Complete from an earlier run; files without a listed size
+			// are checked once their download response arrives
+			if !g.Quiet {
+				fmt.Fprintf(g.Log, "Skipping %s (already complete)\n", output)
+			}
+			g.emit(Event{Type: "skip", URL: item.URL(), ID: item.ID, Name: output, Size: item.Size, MD5: item.MD5})
+		} else {
+			if fetched && !g.DryRun {
+				if err := g.WaitBetween(ctx); err != nil {
+					return err
+				}
+			}
+			fetched = true
+			if !g.Quiet && !g.DryRun {
+				fmt.Fprintf(g.Log, "Downloading %s\n", output)
+			}
+			err = g.retryMismatch(ctx, output, func() error {
+				return g.downloadFile(ctx, item.URL(), output)
+			})
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(g.Log, "Error: %s: %v\n", item.Path, err)
+			g.emit(Event{Type: "error", URL: item.URL(), ID: item.ID, Name: output, Error: err.Error()})
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files in the folder failed", failed, len(files))
+	}
+	return nil
+}
+
+// folderOutput is where a listed item is saved under dir, with the
+// extension of its export format for Google Docs, Sheets and Slides and
+// named after the output template, where it is the index-th file (from 1).
+// With OrganizeByType the folder tree is kept inside a category folder.
+func (g *Client) folderOutput(dir string, item *FileInfo, index int) (string, error) {
+	name := path.Base(item.Path)
+	if kind := nativeKinds[item.MimeType]; kind != "" {
+		format, _, err := g.exportFormatFor(kind)
+		if err != nil {
+			return "", err
+		}
+		name = withExtension(name, format)
+	}
+	name = g.applyTemplate(name, item.ID, index)
+	if g.OrganizeByType {
+		dir = filepath.Join(dir, fileCategory(name, item.MimeType))
+	}
+	return filepath.Join(dir, filepath.FromSlash(path.Dir(item.Path)), name), nil
+}
@@ -0,0 +1,52 @@
+package gget
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// folderServer serves a public folder listing of files and the files
+// themselves by ID.
+func folderServer(files map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/embeddedfolderview" {
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, "<title>Folder</title>")
+			for id, name := range files {
+				fmt.Fprintf(w, `<div class="flip-entry" id="entry-%s"><a href="https://drive.google.com/file/d/%s/view"></a><div class="flip-entry-title">%s</div></div>`, id, id, name)
+			}
+			return
+		}
+		name, ok := files[r.URL.Query().Get("id")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		fmt.Fprint(w, strings.ToUpper(name))
+	})
+}
+
+func TestDownloadFolderOrganizeByType(t *testing.T) {
+	g := newTestClient(t, folderServer(map[string]string{"f1": "photo.jpg", "f2": "notes.txt", "f3": "data.bin"}))
+	g.OrganizeByType = true
+
+	dir := t.TempDir()
+	if err := g.Download(context.Background(), "https://drive.google.com/drive/folders/FOLDER", Options{Output: dir}); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"images/photo.jpg", "docs/notes.txt", "misc/data.bin"} {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+		} else if want := strings.ToUpper(filepath.Base(path)); string(data) != want {
+			t.Errorf("%s holds %q, want %q", path, data, want)
+		}
+	}
+}
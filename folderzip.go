@@ -0,0 +1,99 @@
+package gget
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// downloadFolderZip streams the files of a folder into one zip archive at
+// output, adding each file as it downloads instead of saving it first.
+// Files that fail before sending any data are reported and left out; a
+// failure partway through a file leaves the archive unusable and stops.
+func (g *Client) downloadFolderZip(ctx context.Context, files []*FileInfo, output string) error {
+	path := longPath(output)
+	if err := ensureOutputDir(path); err != nil {
+		return err
+	}
+	out, err := os.Create(path + ".part")
+	if err != nil {
+		return classify(ErrDisk, fmt.Errorf("failed to create output file: %v", err))
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	var failed int
+	for i, item := range files {
+		name, err := g.folderOutput("", item, i+1)
+		if err != nil {
+			return err
+		}
+		header := &zip.FileHeader{Name: filepath.ToSlash(name), Method: zip.Deflate, Modified: item.Modified}
+		if header.Modified.IsZero() {
+			header.Modified = time.Now()
+		}
+
+		if !g.Quiet {
+			fmt.Fprintf(g.Log, "Adding %s\n", header.Name)
+		}
+		entry := &zipEntry{zw: zw, header: header}
+		err = g.downloadFile(withSink(ctx, entry), item.URL(), name)
+		if ctx.Err() != nil {
+			return err
+		}
+		if err != nil && entry.w != nil {
+			return fmt.Errorf("%s: %w (the archive is incomplete)", item.Path, err)
+		}
+		if err != nil {
+			fmt.Fprintf(g.Log, "Error: %s: %v\n", item.Path, err)
+			failed++
+			continue
+		}
+		if _, err := entry.Write(nil); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return classify(ErrDisk, fmt.Errorf("failed to write archive: %v", err))
+	}
+	out.Close()
+	if err := os.Rename(path+".part", path); err != nil {
+		return classify(ErrDisk, fmt.Errorf("failed to rename downloaded file: %v", err))
+	}
+	if !g.Quiet {
+		fmt.Fprintf(g.Log, "Saved %d files to %s\n", len(files)-failed, output)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files in the folder failed", failed, len(files))
+	}
+	return nil
+}
+
+// zipEntry adds its member to the archive on the first write, so that a
+// file whose download fails before any data arrives leaves no trace.
+type zipEntry struct {
+	zw     *zip.Writer
+	header *zip.FileHeader
+	w      io.Writer
+}
+
+func (e *zipEntry) Write(p []byte) (int, error) {
+	if e.w == nil {
+		w, err := e.zw.CreateHeader(e.header)
+		if err != nil {
+			return 0, classify(ErrDisk, fmt.Errorf("failed to write archive: %v", err))
+		}
+		e.w = w
+	}
+	n, err := e.w.Write(p)
+	if err != nil {
+		return n, classify(ErrDisk, fmt.Errorf("failed to write archive: %v", err))
+	}
+	return n, nil
+}
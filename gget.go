@@ -1,81 +1,365 @@
-package main
+package gget
 
 import (
-	"crypto/tls"
-	"flag"
+	"context"
+	"errors"
 	"fmt"
+	"hash"
+	"html"
 	"io"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const (
 	CHUNK_SIZE      = 32 * 1024
 	MAX_RETRY_COUNT = 3
+	MAX_HTML_SIZE   = 10 * 1024 * 1024
 )
 
-type GGet struct {
+var ErrHTMLTooLarge = errors.New("confirmation page exceeded the maximum HTML size before a download link was found")
+
+// Client downloads files from Google Drive. Configure it through its
+// exported fields before starting downloads.
+type Client struct {
+	// Transport is used for every request; adjust its TLS or proxy
+	// settings in place rather than replacing it.
+	Transport *http.Transport
+	// Headers are sent with every request.
+	Headers map[string]string
+	// HostAuth, when set, is the Authorization header for hosts other
+	// than Google Drive; like .netrc credentials it is never sent to
+	// Google.
+	HostAuth string
+	// Auth, when set, authenticates Drive API requests and switches
+	// downloads to the Drive v3 API.
+	Auth TokenSource
+
+	Quiet             bool  // suppress progress output
+	Debug             bool  // print each request's redirects and DNS, connect, TLS and first-byte timings
+	MaxHTMLSize       int64 // cap on a confirmation page read
+	RetryOnTruncation bool  // resume transfers that end short of Content-Length
+	Retries           int
+	RetryWait         time.Duration                 // initial backoff, doubled per attempt
+	ReadTimeout       time.Duration                 // resume transfers that receive nothing for this long
+	MaxTime           time.Duration                 // give up on a download after this long; zero for no limit
+	MinSpeed          int64                         // resume transfers averaging fewer bytes per second over MinSpeedTime
+	MinSpeedTime      time.Duration                 // window for MinSpeed (default MIN_SPEED_TIME)
+	Chmod             func(os.FileMode) os.FileMode // applied to each finished file
+	Chown             *Owner                        // owner given to each finished file; needs root
+	Resume            bool                          // continue from existing .part files
+	Chunks            int                           // concurrent byte ranges per file
+	ExportFormat      string                        // format for Docs/Sheets/Slides/Drawings exports
+	Verify            bool                          // check MD5 checksums when available
+	OrganizeByType    bool                          // sort outputs into category folders
+	QuotaWait         time.Duration                 // keep polling quota-exceeded files this long
+	Wait              time.Duration                 // pause between the files of a folder; see WaitBetween
+	RandomWait        bool                          // vary Wait between half and one and a half times it
+	SkipExisting      bool                          // skip files whose local copy matches the remote size and MD5
+	FolderResume      bool                          // in folder downloads, act as if SkipExisting and Resume were set
+	DryRun            bool                          // print what would be downloaded without writing anything
+	Clobber           ClobberMode                   // what to do when the output file already exists
+	Timestamps        bool                          // keep remote mtimes and skip files whose local copy is not older
+	Directory         string                        // directory for outputs named after the remote file
+	RestrictFilenames FilenameRestriction           // how strictly to clean remote file names
+	NormalizeNames    FilenameNormalization         // Unicode form of local file names
+	Extract           bool                          // unpack .zip, .tar and .tar.gz downloads
+	RemoveArchive     bool                          // delete archives after Extract unpacks them
+	FolderZip         bool                          // save folder downloads as one zip archive
+	DriveOnly         bool                          // reject URLs that are not Google Drive links
+	ChecksumFile      ChecksumAlgorithm             // write file.<algorithm> next to each download
+	KeepShortcuts     bool                          // don't follow Drive shortcuts to their targets
+	IncludeTrashed    bool                          // list and download files in the Drive trash
+	SpaceCheck        bool                          // fail early when the destination lacks room for a file
+	WriteMetadata     bool                          // save the file's metadata as file.info.json next to each download
+	Xattrs            bool                          // record the source URL and Drive ID as extended attributes (Linux only)
+	ConfirmSize       int64                         // ask Confirm before downloads larger than this; zero never asks
+	// Confirm is asked whether to go ahead with a download of size bytes
+	// to name (a file, or a folder and its file count) above ConfirmSize.
+	// Without it such downloads fail.
+	Confirm func(name string, size int64) bool
+	// TrustServerNames uses the file names servers send verbatim, path
+	// separators and ".." included, instead of cleaning them into a single
+	// name inside Directory. Only set it for servers you trust.
+	TrustServerNames bool
+	// Log receives status messages and progress output; it defaults to
+	// os.Stdout.
+	Log io.Writer
+	// Events, when set, receives a JSON Event per line for every start,
+	// progress update, finished file and error.
+	Events io.Writer
+	// ProgressJSON, when set, receives a ProgressRecord per line every
+	// second instead of the progress bar being drawn to Log.
+	ProgressJSON io.Writer
+
 	client       *http.Client
-	headers      map[string]string
 	cookies      []*http.Cookie
 	skipSecurity bool
-	quiet        bool
-}
-
-type DownloadConfig struct {
-	URL          string
-	Output       string
-	Quiet        bool
-	ID           string
-	Fuzzy        bool
-	Speed        string
-	NoProgress   bool
-	UseOriginal  bool
+	dialer       *net.Dialer
+	ipVersion    int               // 4 or 6 to restrict the address family
+	resolve      map[string]string // host:port to the address to dial instead
+	limiter      *rateLimiter
+	eventsMu     sync.Mutex
+	progress     progressGroup
+	include      []string // globs set by SetFilters
+	exclude      []string
+
+	folderNames    sync.Map // folder ID to name, from public listings
+	dryRunFiles    atomic.Int64
+	dryRunBytes    atomic.Int64
+	resourceKeysMu sync.Mutex
+	resourceKeys   map[string]string // file ID to resourcekey
+	outputTemplate string            // set by SetOutputTemplate
+	stats          runStats
+	pause          pauseGate
+	netrc          *netrcFile // set by LoadNetrc
+	waitMu         sync.Mutex // held by WaitBetween while it sleeps
+}
+
+// Options configures a single download.
+type Options struct {
+	// Output is the destination path; when empty the remote file name is
+	// used.
+	Output string
+	// DirectMedia streams an already-resolved https media URL as-is,
+	// skipping all Drive ID and confirmation handling.
+	DirectMedia bool
+	// SkipDownload resolves the download URL and prints it, with any
+	// cookies or authorization it needs, instead of downloading the file.
 	SkipDownload bool
+	// Revision downloads this stored revision of the file instead of its
+	// current content; see ListRevisions.
+	Revision string
+	// Range, when set, fetches only these bytes of a single file. The
+	// slice is saved as-is, without checksum verification.
+	Range *ByteRange
+	// Index is the position of this download in a batch, for the {index}
+	// variable of SetOutputTemplate.
+	Index int
+
+	// OnProgress, when set, is called several times a second with the
+	// bytes written so far, the total size (-1 if unknown) and the speed
+	// in bytes per second, and replaces the progress bar.
+	OnProgress func(bytes int64, total int64, speed float64)
+	// OnComplete is called with the path of each finished file; a folder
+	// download calls it once per file.
+	OnComplete func(output string)
+	// OnDone is called with the "done" Event of each file saved to disk,
+	// whether or not Client.Events is set.
+	OnDone func(event Event)
+	// OnError is called with the error Download is about to return.
+	OnError func(err error)
 }
 
-func NewGGet() *GGet {
-	return &GGet{
+func New() *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	jar, _ := cookiejar.New(nil)
+
+	g := &Client{
+		Transport: transport,
 		client: &http.Client{
-			Timeout: 30 * time.Minute,
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return nil
-			},
+			Transport: transport,
+			Jar:       jar,
 		},
-		headers: map[string]string{
+		Log: os.Stdout,
+		Headers: map[string]string{
 			"User-Agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36",
 		},
-		skipSecurity: true,
-		quiet:        false,
+		skipSecurity:      true,
+		Quiet:             false,
+		MaxHTMLSize:       MAX_HTML_SIZE,
+		RetryOnTruncation: true,
+		Retries:           MAX_RETRY_COUNT,
+		RetryWait:         time.Second,
+		ReadTimeout:       READ_TIMEOUT,
+		Chunks:            1,
+		Verify:            true,
+		SpaceCheck:        true,
+		FolderResume:      true,
+		dialer:            &net.Dialer{Timeout: CONNECT_TIMEOUT, KeepAlive: 30 * time.Second},
+	}
+	transport.DialContext = g.dialContext
+	g.client.CheckRedirect = g.checkRedirect
+	return g
+}
+
+// Download fetches urlStr, which may be any supported Drive URL or a bare
+// file ID, a Dropbox, OneDrive or SharePoint shared link, or any other
+// http(s) URL unless DriveOnly is set. Folder URLs download every file in
+// the folder tree. Links a resolver added with RegisterResolver matches
+// are downloaded from the URL it resolves them to.
+func (g *Client) Download(ctx context.Context, urlStr string, opts Options) error {
+	resolver := g.resolverFor(urlStr, opts.DirectMedia)
+	_, drive := resolver.(driveResolver)
+	parent := ctx
+	ctx, cancel := g.withMaxTime(ctx)
+	defer cancel()
+	ctx = context.WithValue(ctx, optionsKey{}, &opts)
+	err := g.retryQuota(ctx, func() error {
+		switch {
+		case resolver == nil:
+			return errNoFileID
+		case drive && IsFolderURL(urlStr) && opts.Range != nil:
+			return fmt.Errorf("a byte range can only be downloaded from a single file")
+		case drive && IsFolderURL(urlStr):
+			return g.downloadFolder(ctx, urlStr, opts)
+		case opts.SkipDownload:
+			return g.printURL(ctx, resolver, urlStr)
+		}
+		return g.retryMismatch(ctx, opts.Output, func() error {
+			if opts.Revision != "" {
+				return g.downloadRevision(ctx, urlStr, opts.Revision, opts.Output)
+			}
+			return g.downloadResolved(ctx, resolver, urlStr, opts.Output)
+		})
+	})
+	err = g.maxTimeError(parent, err)
+	if err != nil {
+		event := Event{Type: "error", URL: urlStr, Error: err.Error()}
+		if drive {
+			event.ID = ExtractFileID(urlStr)
+		}
+		g.emit(event)
+		if opts.OnError != nil {
+			opts.OnError(err)
+		}
+	}
+	return err
+}
+
+// SetRateLimit caps the combined download speed in bytes per second; zero
+// removes the limit. A SetRateSchedule schedule is kept.
+func (g *Client) SetRateLimit(bytesPerSecond int64) {
+	var schedule []rateWindow
+	if g.limiter != nil {
+		schedule = g.limiter.schedule
+	}
+	if bytesPerSecond <= 0 && schedule == nil {
+		g.limiter = nil
+		return
+	}
+	g.limiter = newRateLimiter(max(bytesPerSecond, 0))
+	g.limiter.schedule = schedule
+}
+
+// ParseSize parses a byte count with an optional K, M, G or T suffix
+// (powers of 1024), e.g. "512K" or "1.5G".
+func ParseSize(str string) (int64, error) {
+	s := strings.TrimSpace(strings.ToUpper(str))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+	multiplier := int64(1)
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			s = s[:len(s)-1]
+		}
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", str)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// ParseChmod parses an octal mode such as "0644" or a symbolic change such
+// as "+x" or "u+rw,go-w" into a function applied to the file's current mode.
+func ParseChmod(spec string) (func(os.FileMode) os.FileMode, error) {
+	if mode, err := strconv.ParseUint(spec, 8, 32); err == nil {
+		if mode > 0777 {
+			return nil, fmt.Errorf("invalid mode %q", spec)
+		}
+		return func(os.FileMode) os.FileMode { return os.FileMode(mode) }, nil
+	}
+
+	symbolicRe := regexp.MustCompile(`^([ugoa]*)([+=-])([rwx]*)$`)
+	var clauses [][]string
+	for _, clause := range strings.Split(spec, ",") {
+		matches := symbolicRe.FindStringSubmatch(clause)
+		if matches == nil {
+			return nil, fmt.Errorf("invalid mode %q", spec)
+		}
+		clauses = append(clauses, matches)
 	}
+
+	return func(mode os.FileMode) os.FileMode {
+		for _, clause := range clauses {
+			who, op, perms := clause[1], clause[2], clause[3]
+			if who == "" || strings.Contains(who, "a") {
+				who = "ugo"
+			}
+
+			var bits, mask os.FileMode
+			for _, p := range perms {
+				bits |= map[rune]os.FileMode{'r': 4, 'w': 2, 'x': 1}[p]
+			}
+			var shifted os.FileMode
+			for _, w := range who {
+				shift := map[rune]uint{'u': 6, 'g': 3, 'o': 0}[w]
+				shifted |= bits << shift
+				mask |= 7 << shift
+			}
+
+			switch op {
+			case "+":
+				mode |= shifted
+			case "-":
+				mode &^= shifted
+			case "=":
+				mode = mode&^mask | shifted
+			}
+		}
+		return mode
+	}, nil
 }
 
-// Improved URL parsing to handle more formats
-func (g *GGet) extractFileID(urlStr string) string {
+// fileIDPatterns match the ID in the Drive URL shapes gget accepts, with
+// or without a /u/N/ account segment.
+var fileIDPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`/(?:file|document|spreadsheets|presentation|drawings)/(?:u/\d+/)?d/([^/?#]+)`),
+	regexp.MustCompile(`script\.google\.com/(?:home/projects|(?:macros/)?d)/([^/?#]+)`),
+	regexp.MustCompile(`[?&#]id=([^&#]+)`),
+	regexp.MustCompile(`/files/([^/?#]+)`),
+	regexp.MustCompile(`folders/([^/?#]+)`),
+}
+
+// ExtractFileID returns the file or folder ID in urlStr, or "" if it has
+// none. It accepts bare IDs and the usual Drive link shapes:
+//
+//	https://drive.google.com/file/d/ID/view?usp=drivesdk
+//	https://drive.google.com/open?id=ID
+//	https://drive.google.com/uc?export=view&id=ID
+//	https://drive.google.com/drive/u/1/folders/ID
+//	https://docs.google.com/document/d/ID/edit
+//	https://script.google.com/home/projects/ID/edit
+//	https://drive.google.com/#?id=ID
+func ExtractFileID(urlStr string) string {
 	// Handle direct ID input
 	if !strings.Contains(urlStr, "/") && !strings.Contains(urlStr, "\\") {
 		return urlStr
 	}
 
-	patterns := []string{
-		`/file/d/([^/]+)`,
-		`id=([^&]+)`,
-		`/files/([^/]+)`,
-		`/document/d/([^/]+)`,
-		`/spreadsheets/d/([^/]+)`,
-		`/presentation/d/([^/]+)`,
-		`folders/([^/]+)`,
-	}
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		matches := re.FindStringSubmatch(urlStr)
-		if len(matches) > 1 {
+	for _, re := range fileIDPatterns {
+		if matches := re.FindStringSubmatch(urlStr); len(matches) > 1 {
 			return matches[1]
 		}
 	}
@@ -91,7 +375,7 @@ func (g *GGet) extractFileID(urlStr string) string {
 	return ""
 }
 
-func (g *GGet) getConfirmToken(resp *http.Response) string {
+func (g *Client) getConfirmToken(resp *http.Response) string {
 	for _, cookie := range resp.Cookies() {
 		if strings.HasPrefix(cookie.Name, "download_warning") {
 			return cookie.Value
@@ -100,16 +384,10 @@ func (g *GGet) getConfirmToken(resp *http.Response) string {
 	return ""
 }
 
-func (g *GGet) getFileName(resp *http.Response, defaultName string) string {
+func (g *Client) getFileName(resp *http.Response, defaultName string) string {
 	// Try Content-Disposition header
-	if cd := resp.Header.Get("Content-Disposition"); cd != "" {
-		if re := regexp.MustCompile(`filename\*?=(?:UTF-8'[^']*')?([^;]+)`); re.MatchString(cd) {
-			matches := re.FindStringSubmatch(cd)
-			if len(matches) > 1 {
-				filename := strings.Trim(matches[1], `"'`)
-				return filename
-			}
-		}
+	if filename := dispositionFilename(resp.Header.Get("Content-Disposition")); filename != "" {
+		return filename
 	}
 
 	// Try URL path
@@ -126,85 +404,548 @@ func (g *GGet) getFileName(resp *http.Response, defaultName string) string {
 	return defaultName
 }
 
-func (g *GGet) downloadWithProgress(resp *http.Response, output string) error {
-	out, err := os.Create(output + ".part") // Use .part extension while downloading
+// outputName picks the local path for a response when no explicit output
+// was requested, sorting it into a category folder with -organize-by-type.
+func (g *Client) outputName(ctx context.Context, resp *http.Response, id string, defaultName string) string {
+	return g.placeOutput(ctx, g.getFileName(resp, defaultName), id, resp.Header.Get("Content-Type"))
+}
+
+// placeOutput turns the remote name of file id into a safe local output
+// path under g.Directory, named after the output template if one is set.
+// With TrustServerNames the name is used as the server sent it.
+func (g *Client) placeOutput(ctx context.Context, name string, id string, contentType string) string {
+	if g.TrustServerNames && name != "" {
+		name = filepath.FromSlash(g.normalizeName(name))
+	} else {
+		name = g.sanitizeName(name)
+	}
+	name = g.applyTemplate(name, id, batchIndex(ctx))
+	if g.OrganizeByType {
+		name = filepath.Join(fileCategory(name, contentType), name)
+	}
+	return filepath.Join(g.Directory, name)
+}
+
+var categoryExtensions = map[string][]string{
+	"images":   {".jpg", ".jpeg", ".png", ".gif", ".bmp", ".webp", ".svg", ".tif", ".tiff", ".heic", ".ico"},
+	"videos":   {".mp4", ".mkv", ".mov", ".avi", ".webm", ".m4v", ".wmv", ".flv"},
+	"audio":    {".mp3", ".wav", ".flac", ".aac", ".ogg", ".m4a", ".opus"},
+	"docs":     {".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx", ".odt", ".ods", ".odp", ".txt", ".md", ".rtf", ".csv", ".epub"},
+	"archives": {".zip", ".tar", ".gz", ".tgz", ".bz2", ".xz", ".7z", ".rar", ".zst"},
+}
+
+// fileCategory maps a file to a subfolder name by extension, falling back
+// to the content type and finally to "misc".
+func fileCategory(name string, contentType string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	for category, extensions := range categoryExtensions {
+		for _, e := range extensions {
+			if ext == e {
+				return category
+			}
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return "images"
+	case strings.HasPrefix(contentType, "video/"):
+		return "videos"
+	case strings.HasPrefix(contentType, "audio/"):
+		return "audio"
+	case strings.HasPrefix(contentType, "text/"), strings.HasPrefix(contentType, "application/pdf"):
+		return "docs"
+	case strings.Contains(contentType, "zip"), strings.Contains(contentType, "tar"), strings.Contains(contentType, "compressed"):
+		return "archives"
+	}
+	return "misc"
+}
+
+// transfer carries the per-file state of a single download.
+type transfer struct {
+	output   string
+	md5      string    // expected hex MD5 checksum, if known
+	url      string    // the URL as given by the caller
+	id       string    // Drive file ID, if any
+	modified time.Time // remote modification time, if known
+	started  time.Time
+	hash     hash.Hash // ChecksumFile hash of the bytes written so far
+	// callbacks from the Options of the Download call, if any
+	onProgress func(bytes int64, total int64, speed float64)
+	onComplete func(output string)
+	onDone     func(event Event)
+
+	byteRange   *ByteRange // the slice of the file to fetch, if not all of it
+	meta        *driveFile // Drive API metadata, if fetched through the API
+	contentType string     // of the response
+}
+
+// path is t.output as passed to the file system, in the extended-length
+// form Windows needs for long paths; messages and events keep t.output.
+func (t *transfer) path() string {
+	return longPath(t.output)
+}
+
+// saveResponse writes resp to t.output, first resuming from an existing
+// .part file with a Range request when -continue is set. An output of "-"
+// streams the file to stdout, and a context from withSink streams it to
+// the sink's writer.
+func (g *Client) saveResponse(ctx context.Context, resp *http.Response, t *transfer) error {
+	if opts, ok := ctx.Value(optionsKey{}).(*Options); ok {
+		t.onProgress, t.onComplete, t.onDone = opts.OnProgress, opts.OnComplete, opts.OnDone
+		t.byteRange = opts.Range
+	}
+	t.contentType = resp.Header.Get("Content-Type")
+	if t.byteRange != nil {
+		rangeResp, err := g.requestRange(ctx, resp.Request.URL.String(), t.byteRange.Start, t.byteRange.End)
+		if err != nil {
+			return fmt.Errorf("failed to fetch range %s: %w", t.byteRange, err)
+		}
+		resp.Body.Close()
+		resp = rangeResp
+		defer resp.Body.Close()
+		// A slice never matches the checksum of the whole file
+		t.md5 = ""
+	}
+	if w, ok := ctx.Value(sinkKey{}).(io.Writer); ok {
+		return g.streamResponse(ctx, resp, t, w)
+	}
+	if t.output == "-" {
+		return g.streamResponse(ctx, resp, t, os.Stdout)
+	}
+	if g.skipExisting(ctx) && upToDate(t, resp.ContentLength) {
+		if !g.Quiet {
+			fmt.Fprintf(g.Log, "Skipping %s (already up to date)\n", t.output)
+		}
+		g.emit(Event{Type: "skip", URL: t.url, ID: t.id, Name: t.output, Size: max(resp.ContentLength, 0), MD5: t.md5})
+		return nil
+	}
+
+	if t.modified.IsZero() {
+		t.modified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	}
+	if g.Timestamps && notOlder(t, resp.ContentLength) {
+		if !g.Quiet {
+			fmt.Fprintf(g.Log, "Skipping %s (local copy is not older than the remote file)\n", t.output)
+		}
+		g.emit(Event{Type: "skip", URL: t.url, ID: t.id, Name: t.output})
+		return nil
+	}
+
+	if exists(t.path()) {
+		switch g.Clobber {
+		case NO_CLOBBER:
+			if !g.Quiet {
+				fmt.Fprintf(g.Log, "Skipping %s (already exists)\n", t.output)
+			}
+			g.emit(Event{Type: "skip", URL: t.url, ID: t.id, Name: t.output})
+			return nil
+		case AUTO_RENAME:
+			t.output = availableName(t.output)
+		}
+	}
+
+	if g.DryRun {
+		g.printDryRun(t, resp.ContentLength)
+		return nil
+	}
+
+	if err := g.confirmSize(ctx, t.output, resp.ContentLength); err != nil {
+		return err
+	}
+	if err := ensureOutputDir(t.path()); err != nil {
+		return err
+	}
+	need := resp.ContentLength
+	if info, err := os.Stat(t.path() + ".part"); err == nil && g.resume(ctx) {
+		need -= info.Size()
+	}
+	if err := g.checkSpace(t.path(), need); err != nil {
+		return err
+	}
+	g.emitStart(t, resp.ContentLength)
+
+	if t.byteRange != nil {
+		return g.downloadWithProgress(ctx, resp, t, 0)
+	}
+
+	var offset int64
+	if g.resume(ctx) {
+		if state := loadState(t.path()); state != nil && len(state.Segments) > 0 {
+			// A segmented .part file is preallocated, so only the saved
+			// segment positions say how much of it is valid
+			if state.Size == resp.ContentLength && acceptsRanges(resp) {
+				resp.Body.Close()
+				if !g.Quiet {
+					fmt.Fprintf(g.Log, "Resuming segmented download at %d of %d bytes\n", state.Written, state.Size)
+				}
+				return g.downloadSegmented(ctx, resp.Request.URL.String(), t, resp.ContentLength, state.Segments)
+			}
+		} else if info, err := os.Stat(t.path() + ".part"); err == nil && info.Size() > 0 {
+			offset = info.Size()
+		}
+	}
+
+	switch {
+	case offset == 0:
+	case offset == resp.ContentLength:
+		// The previous run fetched everything but never renamed the file
+		return g.finalizeOutput(t)
+	case resp.ContentLength > 0 && offset > resp.ContentLength:
+		if !g.Quiet {
+			fmt.Fprintln(g.Log, "Partial file is larger than the remote file, restarting download")
+		}
+		offset = 0
+	default:
+		rangeResp, err := g.requestRange(ctx, resp.Request.URL.String(), offset, -1)
+		if err != nil {
+			if !g.Quiet {
+				fmt.Fprintf(g.Log, "Cannot resume (%v), restarting download\n", err)
+			}
+			offset = 0
+			break
+		}
+		if _, _, total, _ := parseContentRange(rangeResp.Header.Get("Content-Range")); resp.ContentLength > 0 && total != resp.ContentLength {
+			// The remote file changed since the partial download
+			rangeResp.Body.Close()
+			if !g.Quiet {
+				fmt.Fprintln(g.Log, "Remote file size changed, restarting download")
+			}
+			offset = 0
+			break
+		}
+		resp.Body.Close()
+		resp = rangeResp
+		defer resp.Body.Close()
+		if !g.Quiet {
+			fmt.Fprintf(g.Log, "Resuming download at byte %d\n", offset)
+		}
+	}
+
+	if offset == 0 && g.Chunks > 1 && resp.ContentLength > 0 && acceptsRanges(resp) {
+		resp.Body.Close()
+		return g.downloadSegmented(ctx, resp.Request.URL.String(), t, resp.ContentLength, nil)
+	}
+
+	return g.downloadWithProgress(ctx, resp, t, offset)
+}
+
+func (g *Client) downloadWithProgress(ctx context.Context, resp *http.Response, t *transfer, offset int64) error {
+	// Use .part extension while downloading
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	out, err := os.OpenFile(t.path()+".part", flags, 0666)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %v", err)
+		return classify(ErrDisk, fmt.Errorf("failed to create output file: %v", err))
 	}
 	defer out.Close()
 
-	fileSize := resp.ContentLength
-	progress := 0
-	lastProgressUpdate := time.Now()
-	buffer := make([]byte, 32*1024) // 32KB buffer
+	var w io.Writer = out
+	if t.hash, err = g.sidecarHash(t.path()+".part", offset); err != nil {
+		return err
+	}
+	if t.hash != nil {
+		w = io.MultiWriter(out, t.hash)
+	}
+
+	progress, fileSize, err := g.copyResponse(ctx, resp, t, w, offset)
+	if err != nil {
+		if ctx.Err() != nil {
+			return g.interrupted(ctx, t, out, partState{URL: resp.Request.URL.String(), Size: fileSize, Written: progress})
+		}
+		return err
+	}
+
+	out.Close()
+	return g.finalizeOutput(t)
+}
+
+// copyResponse writes the body of resp to out, which already holds offset
+// bytes, and returns the number of bytes written in total along with the
+// expected size (-1 if unknown).
+func (g *Client) copyResponse(ctx context.Context, resp *http.Response, t *transfer, out io.Writer, offset int64) (int64, int64, error) {
+	fileSize := int64(-1)
+	if resp.ContentLength >= 0 {
+		fileSize = offset + resp.ContentLength
+	}
+	bar := g.newProgress(t, fileSize, offset)
+	defer bar.close()
+	progress, err := g.copyWithProgress(ctx, out, resp.Body, offset, bar)
+
+	// Pick up where the transfer stopped with a Range request, both after a
+	// network error and after a clean EOF short of Content-Length, which
+	// means the transfer was silently truncated.
+	for attempt := 1; ; attempt++ {
+		truncated := err == nil && fileSize > 0 && progress < fileSize
+		if err == nil && !truncated {
+			break
+		}
+
+		if ctx.Err() != nil {
+			return progress, fileSize, ctx.Err()
+		}
+		if (truncated && !g.RetryOnTruncation) || (err != nil && !isReadError(err)) ||
+			attempt > g.Retries || !acceptsRanges(resp) {
+			if truncated {
+				return progress, fileSize, classify(ErrNetwork, fmt.Errorf("incomplete download: received %d of %d bytes", progress, fileSize))
+			}
+			if isReadError(err) {
+				return progress, fileSize, classify(ErrNetwork, err)
+			}
+			return progress, fileSize, err
+		}
+		if err != nil {
+			if g.sleepBeforeRetry(ctx, attempt, err) != nil {
+				continue
+			}
+		}
+
+		start, end := progress, int64(-1)
+		if t.byteRange != nil {
+			start, end = t.byteRange.Start+progress, t.byteRange.End
+		}
+		rangeResp, rangeErr := g.requestRange(ctx, resp.Request.URL.String(), start, end)
+		if rangeErr != nil {
+			return progress, fileSize, fmt.Errorf("failed to resume download: %w", rangeErr)
+		}
+		progress, err = g.copyWithProgress(ctx, out, rangeResp.Body, progress, bar)
+		rangeResp.Body.Close()
+	}
+
+	bar.finish(progress)
+	return progress, fileSize, nil
+}
+
+// finalizeOutput verifies the completed .part file and moves it into place.
+func (g *Client) finalizeOutput(t *transfer) error {
+	if err := g.verifyChecksum(t); err != nil {
+		return err
+	}
+
+	// Rename .part file to final filename
+	if err := os.Rename(t.path()+".part", t.path()); err != nil {
+		return classify(ErrDisk, fmt.Errorf("failed to rename downloaded file: %v", err))
+	}
+	os.Remove(statePath(t.path()))
+
+	if g.Timestamps && !t.modified.IsZero() {
+		if err := os.Chtimes(t.path(), time.Time{}, t.modified); err != nil {
+			return classify(ErrDisk, fmt.Errorf("failed to set modification time: %v", err))
+		}
+	}
+
+	if g.Chmod != nil {
+		info, err := os.Stat(t.path())
+		if err != nil {
+			return classify(ErrDisk, fmt.Errorf("failed to stat downloaded file: %v", err))
+		}
+		if err := os.Chmod(t.path(), g.Chmod(info.Mode().Perm())); err != nil {
+			return classify(ErrDisk, fmt.Errorf("failed to set file permissions: %v", err))
+		}
+	}
+	if g.Chown != nil {
+		if err := os.Chown(t.path(), g.Chown.UID, g.Chown.GID); err != nil {
+			return classify(ErrDisk, fmt.Errorf("failed to set file owner: %v", err))
+		}
+	}
+	if g.Xattrs {
+		g.writeXattrs(t)
+	}
+
+	if g.ChecksumFile != NO_CHECKSUM {
+		if err := g.writeChecksumFile(t.path(), t.hash); err != nil {
+			return err
+		}
+	}
+	if g.WriteMetadata {
+		if err := writeMetadata(t); err != nil {
+			return err
+		}
+	}
+
+	g.emitDone(t)
+	if t.onComplete != nil {
+		t.onComplete(t.output)
+	}
+
+	if g.Extract {
+		return g.extractOutput(t)
+	}
+	return nil
+}
+
+// copyWithProgress copies body into out until EOF, reporting progress on
+// bar, and returns the new total byte count.
+func (g *Client) copyWithProgress(ctx context.Context, out io.Writer, body io.Reader, progress int64, bar *progressBar) (int64, error) {
+	buffer := make([]byte, CHUNK_SIZE)
 
 	for {
-		n, err := resp.Body.Read(buffer)
+		if err := g.waitPaused(ctx); err != nil {
+			return progress, err
+		}
+		n, err := body.Read(buffer)
 		if n > 0 {
+			if g.limiter != nil {
+				if err := g.limiter.wait(ctx, n); err != nil {
+					return progress, err
+				}
+			}
 			_, writeErr := out.Write(buffer[:n])
 			if writeErr != nil {
-				return fmt.Errorf("failed to write to file: %v", writeErr)
-			}
-			progress += n
-
-			// Update progress every 100ms
-			if !g.quiet && time.Since(lastProgressUpdate) > 100*time.Millisecond {
-				if fileSize > 0 {
-					percentage := float64(progress) / float64(fileSize) * 100
-					fmt.Printf("\rDownloading... %.1f%% (%d/%d bytes)", percentage, progress, fileSize)
-				} else {
-					fmt.Printf("\rDownloading... %d bytes", progress)
-				}
-				lastProgressUpdate = time.Now()
+				return progress, classify(ErrDisk, fmt.Errorf("failed to write to file: %v", writeErr))
 			}
+			progress += int64(n)
+			g.stats.addBytes(n)
+
+			bar.update(progress)
 		}
 		if err == io.EOF {
-			break
+			return progress, nil
+		}
+		if err == io.ErrUnexpectedEOF && bar.total > 0 {
+			// The connection closed short of Content-Length; callers
+			// detect the shortfall and resume
+			return progress, nil
 		}
 		if err != nil {
-			return fmt.Errorf("download error: %v", err)
+			if ctx.Err() != nil {
+				return progress, ctx.Err()
+			}
+			return progress, &readError{err}
 		}
 	}
+}
 
-	if !g.quiet {
-		fmt.Println() // New line after progress
+// requestRange requests the bytes of urlStr from start to end (or to the
+// end of the file when end is negative) and checks that the server
+// answered with the matching partial content.
+func (g *Client) requestRange(ctx context.Context, urlStr string, start, end int64) (*http.Response, error) {
+	req, err := g.newRequest(ctx, urlStr)
+	if err != nil {
+		return nil, err
+	}
+	if end < 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", start))
+	} else {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
 	}
 
-	// Rename .part file to final filename
-	if err := os.Rename(output+".part", output); err != nil {
-		return fmt.Errorf("failed to rename downloaded file: %v", err)
+	resp, err := g.do(req)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, fmt.Errorf("server does not support range requests: %s", resp.Status)
+	}
+
+	rangeStart, _, _, err := parseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil || rangeStart != start {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected Content-Range %q for offset %d", resp.Header.Get("Content-Range"), start)
+	}
+
+	return resp, nil
+}
+
+// parseContentRange parses a "bytes start-end/total" header; total is -1
+// when the server reports it as unknown.
+func parseContentRange(header string) (start, end, total int64, err error) {
+	total = -1
+	var totalStr string
+	if _, err = fmt.Sscanf(header, "bytes %d-%d/%s", &start, &end, &totalStr); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	if totalStr != "*" {
+		if total, err = strconv.ParseInt(totalStr, 10, 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+		}
+	}
+	return start, end, total, nil
+}
+
+func acceptsRanges(resp *http.Response) bool {
+	return resp.Header.Get("Accept-Ranges") == "bytes"
 }
 
-// Modify the getURLFromConfirmation function
-func (g *GGet) getURLFromConfirmation(contents string) (string, error) {
-	// Try finding the form first
-	formRe := regexp.MustCompile(`<form.+?id="download-form".+?action="(.+?)"`)
-	formMatches := formRe.FindStringSubmatch(contents)
-	if len(formMatches) > 1 {
-		formAction := formMatches[1]
-		formAction = strings.Replace(formAction, "&amp;", "&", -1)
+var (
+	formRe     = regexp.MustCompile(`(?is)<form\b([^>]*)>(.*?)</form>`)
+	inputRe    = regexp.MustCompile(`(?is)<input\b([^>]*)>`)
+	htmlAttrRe = regexp.MustCompile(`(?is)([a-z_:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
 
-		// Extract hidden input values
-		inputRe := regexp.MustCompile(`<input.+?name="([^"]+)".+?value="([^"]+)"`)
-		inputs := inputRe.FindAllStringSubmatch(contents, -1)
+// htmlAttrs parses the attributes of an HTML start tag in any order.
+func htmlAttrs(tag string) map[string]string {
+	attrs := make(map[string]string)
+	for _, match := range htmlAttrRe.FindAllStringSubmatch(tag, -1) {
+		attrs[strings.ToLower(match[1])] = html.UnescapeString(match[2] + match[3])
+	}
+	return attrs
+}
 
-		parsedURL, err := url.Parse(formAction)
-		if err != nil {
-			return "", err
+// confirmationForm finds the download form on a confirmation page, both
+// the older docs.google.com layout and the drive.usercontent.google.com
+// one with its uuid/confirm/at hidden fields, and returns the URL it
+// submits to. Google's download endpoint takes the same fields in the
+// query string whether the form is sent by GET or POST, so the result
+// can always be fetched with a plain GET.
+func confirmationForm(contents string, base *url.URL) (string, bool) {
+	if formURL, ok := parsedConfirmationForm(contents, base); ok {
+		return formURL, true
+	}
+
+	// Fall back to scraping, in case the page is too broken to parse
+	for _, form := range formRe.FindAllStringSubmatch(contents, -1) {
+		attrs := htmlAttrs(form[1])
+		if attrs["id"] != "download-form" && !strings.Contains(attrs["action"], "download") {
+			continue
 		}
 
-		query := parsedURL.Query()
-		for _, input := range inputs {
-			if len(input) == 3 && input[1] != "" {
-				query.Set(input[1], input[2])
+		fields := url.Values{}
+		for _, input := range inputRe.FindAllStringSubmatch(form[2], -1) {
+			inputAttrs := htmlAttrs(input[1])
+			if name := inputAttrs["name"]; name != "" {
+				fields.Set(name, inputAttrs["value"])
 			}
 		}
+		if formURL, ok := submitURL(attrs["action"], fields, base); ok {
+			return formURL, true
+		}
+	}
+	return "", false
+}
 
-		parsedURL.RawQuery = query.Encode()
-		return parsedURL.String(), nil
+// submitURL resolves a form action against base and adds the form
+// fields to its query string.
+func submitURL(action string, fields url.Values, base *url.URL) (string, bool) {
+	actionURL, err := url.Parse(action)
+	if err != nil {
+		return "", false
+	}
+	if base != nil {
+		actionURL = base.ResolveReference(actionURL)
+	}
+
+	query := actionURL.Query()
+	for name := range fields {
+		query.Set(name, fields.Get(name))
+	}
+	actionURL.RawQuery = query.Encode()
+	return actionURL.String(), true
+}
+
+// getURLFromConfirmation extracts the download URL from a confirmation
+// page served from base.
+func (g *Client) getURLFromConfirmation(contents string, base *url.URL) (string, error) {
+	if isQuotaPage(contents) {
+		return "", ErrQuotaExceeded
+	}
+
+	if formURL, ok := confirmationForm(contents, base); ok {
+		return formURL, nil
 	}
 
 	// Try the download link pattern
@@ -229,122 +970,166 @@ func (g *GGet) getURLFromConfirmation(contents string) (string, error) {
 	re = regexp.MustCompile(`<p class="uc-error-subcaption">(.*?)</p>`)
 	matches = re.FindStringSubmatch(contents)
 	if len(matches) > 1 {
-		return "", fmt.Errorf("drive error: %s", matches[1])
+		if strings.Contains(strings.ToLower(matches[1]), "trash") {
+			return "", trashedError("the file")
+		}
+		return "", classify(ErrPermissionDenied, fmt.Errorf("drive error: %s", matches[1]))
 	}
 
 	return "", fmt.Errorf("cannot retrieve the download link")
 }
 
 // Modify the downloadFile method
-func (g *GGet) downloadFile(urlStr string, output string) error {
-	fileID := g.extractFileID(urlStr)
+func (g *Client) downloadFile(ctx context.Context, urlStr string, output string) error {
+	fileID := ExtractFileID(urlStr)
 	if fileID == "" {
-		return fmt.Errorf("could not extract file ID from URL")
+		return errNoFileID
 	}
+	g.rememberResourceKey(fileID, urlStr)
 
-	initialURL := fmt.Sprintf("https://drive.google.com/uc?id=%s&export=download", fileID)
-
-	// First request to get the confirmation page
-	req, err := http.NewRequest("GET", initialURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
+	if g.Auth != nil {
+		return g.downloadFromAPI(ctx, fileID, output)
 	}
 
-	for key, value := range g.headers {
-		req.Header.Set(key, value)
+	if kind := documentKind(urlStr); kind != "" {
+		return g.downloadExport(ctx, kind, fileID, output)
 	}
 
-	resp, err := g.client.Do(req)
+	downloadURL, resp, err := g.resolveFile(ctx, fileID)
 	if err != nil {
-		return fmt.Errorf("request failed: %v", err)
+		return err
 	}
-	defer resp.Body.Close()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %v", err)
-	}
-	bodyString := string(bodyBytes)
+	// Make the actual download request, unless the first response was
+	// already the file
+	if resp == nil {
+		req, err := g.newRequest(ctx, downloadURL)
+		if err != nil {
+			return fmt.Errorf("failed to create download request: %v", err)
+		}
 
-	var downloadURL string
-	if strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
-		downloadURL, err = g.getURLFromConfirmation(bodyString)
+		resp, err = g.do(req)
 		if err != nil {
-			return fmt.Errorf("failed to get download URL: %v", err)
+			return fmt.Errorf("download request failed: %w", err)
 		}
-	} else {
-		downloadURL = initialURL
 	}
+	defer resp.Body.Close()
 
-	// Make the actual download request
-	req, err = http.NewRequest("GET", downloadURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create download request: %v", err)
+	// Get or generate output filename
+	if output == "" {
+		output = g.outputName(ctx, resp, fileID, fmt.Sprintf("gdrive_%s", fileID))
 	}
 
-	for key, value := range g.headers {
-		req.Header.Set(key, value)
+	return g.saveResponse(ctx, resp, &transfer{output: output, md5: headerMD5(resp), url: urlStr, id: fileID})
+}
+
+// resolveFile requests the public download endpoint of fileID and
+// follows the virus-scan confirmation page, if any, to the URL that
+// serves the file itself. When Drive serves the file right away, that
+// response is returned as well for the caller to stream and close.
+func (g *Client) resolveFile(ctx context.Context, fileID string) (string, *http.Response, error) {
+	initialURL := g.withResourceKey(fmt.Sprintf("https://drive.google.com/uc?id=%s&export=download", fileID), fileID)
+
+	// First request to get the confirmation page
+	req, err := g.newRequest(ctx, initialURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create request: %v", err)
 	}
 
-	resp, err = g.client.Do(req)
+	resp, err := g.do(req)
 	if err != nil {
-		return fmt.Errorf("download request failed: %v", err)
+		return "", nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	// Only an HTML page needs parsing; anything else is the file itself
+	// and is streamed from this response instead of being read into memory
+	if !strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return "", nil, statusError(resp.StatusCode, fmt.Errorf("unexpected response status: %s", resp.Status))
+		}
+		return initialURL, resp, nil
 	}
 	defer resp.Body.Close()
 
-	// Get or generate output filename
-	if output == "" {
-		output = g.getFileName(resp, fmt.Sprintf("gdrive_%s", fileID))
+	// Cap the read so a runaway HTML response cannot exhaust memory
+	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, g.MaxHTMLSize+1))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read response: %v", err)
+	}
+	truncated := int64(len(bodyBytes)) > g.MaxHTMLSize
+	if truncated {
+		bodyBytes = bodyBytes[:g.MaxHTMLSize]
 	}
 
-	// Ensure the output directory exists
-	if dir := filepath.Dir(output); dir != "." {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create output directory: %v", err)
+	downloadURL, err := g.getURLFromConfirmation(string(bodyBytes), resp.Request.URL)
+	if err != nil {
+		if truncated {
+			return "", nil, fmt.Errorf("%w (limit %d bytes)", ErrHTMLTooLarge, g.MaxHTMLSize)
 		}
+		if errors.Is(err, ErrQuotaExceeded) {
+			return "", nil, err
+		}
+		return "", nil, fmt.Errorf("failed to get download URL: %w", err)
 	}
 
-	return g.downloadWithProgress(resp, output)
+	return downloadURL, nil, nil
 }
 
-func main() {
-	var (
-		outputFile = flag.String("o", "", "Output filename")
-		quiet      = flag.Bool("q", false, "Quiet mode (no progress)")
-		noCheck    = flag.Bool("no-check-certificate", false, "Skip certificate verification")
-		version    = flag.Bool("V", false, "Show version")
-		fileID     = flag.String("id", "", "Google Drive file ID")
-	)
-
-	flag.Parse()
+// SetProxy routes all requests through proxyURL instead of the proxy
+// configured by HTTP_PROXY/HTTPS_PROXY/NO_PROXY.
+func (g *Client) SetProxy(proxyURL string) error {
+	parsedURL, err := url.Parse(proxyURL)
+	if err != nil || parsedURL.Host == "" {
+		return fmt.Errorf("invalid proxy URL %q", proxyURL)
+	}
 
-	if *version {
-		fmt.Println("gget version 1.0.0")
-		return
+	switch parsedURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("unsupported proxy scheme %q", parsedURL.Scheme)
 	}
 
-	downloader := NewGGet()
-	downloader.quiet = *quiet
+	g.Transport.Proxy = http.ProxyURL(parsedURL)
+	return nil
+}
 
-	// Handle certificate verification
-	if *noCheck {
-		downloader.client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
+// newRequest builds a GET request carrying the configured headers, plus
+// the bearer token and any resource keys for Drive API URLs when logged
+// in.
+func (g *Client) newRequest(ctx context.Context, urlStr string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
+	if err != nil {
+		return nil, err
 	}
-
-	var url string
-	if *fileID != "" {
-		url = *fileID
-	} else if flag.NArg() > 0 {
-		url = flag.Arg(0)
-	} else {
-		fmt.Println("Usage: gget [-o output_filename] [-q] [-id file_id] [-fuzzy] <google_drive_url>")
-		os.Exit(1)
+	for key, value := range g.Headers {
+		req.Header.Set(key, value)
+	}
+	if g.HostAuth != "" && isGenericURL(req.URL) {
+		req.Header.Set("Authorization", g.HostAuth)
+	}
+	if login := g.netrcFor(req.URL); login != nil && req.Header.Get("Authorization") == "" {
+		req.SetBasicAuth(login.login, login.password)
+	}
+	if g.Auth != nil && strings.HasPrefix(urlStr, DRIVE_API_URL) {
+		token, err := g.Auth.Token()
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		if keys := g.resourceKeysHeader(); keys != "" {
+			req.Header.Set("X-Goog-Drive-Resource-Keys", keys)
+		}
 	}
+	return req, nil
+}
 
-	if err := downloader.downloadFile(url, *outputFile); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+// ensureOutputDir creates the parent directory of output if needed.
+func ensureOutputDir(output string) error {
+	if dir := filepath.Dir(output); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return classify(ErrDisk, fmt.Errorf("failed to create output directory: %v", err))
+		}
 	}
+	return nil
 }
@@ -0,0 +1,77 @@
+package gget
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestClient returns a quiet client that sends the requests for every
+// host to handler.
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	g := New()
+	g.Quiet, g.Log = true, io.Discard
+	g.Transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	g.Transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	return g
+}
+
+func TestExtractFileID(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"1AbC-dEf_gHi", "1AbC-dEf_gHi"},
+		{"https://drive.google.com/file/d/1AbC/view", "1AbC"},
+		{"https://drive.google.com/file/d/1AbC/view?usp=sharing", "1AbC"},
+		{"https://drive.google.com/file/d/1AbC/view?usp=drivesdk", "1AbC"},
+		{"https://drive.google.com/file/u/1/d/1AbC/view", "1AbC"},
+		{"https://drive.google.com/open?id=1AbC", "1AbC"},
+		{"https://drive.google.com/uc?id=1AbC&export=download", "1AbC"},
+		{"https://drive.google.com/uc?export=view&id=1AbC", "1AbC"},
+		{"https://drive.google.com/drive/folders/1Fold", "1Fold"},
+		{"https://drive.google.com/drive/u/1/folders/1Fold?usp=sharing", "1Fold"},
+		{"https://drive.google.com/drive/my-drive#id=1AbC", "1AbC"},
+		{"https://docs.google.com/document/d/1Doc/edit", "1Doc"},
+		{"https://docs.google.com/spreadsheets/u/0/d/1Sheet/edit#gid=0", "1Sheet"},
+		{"https://script.google.com/d/1Script/edit", "1Script"},
+		{"https://www.googleapis.com/drive/v3/files/1AbC?alt=media", "1AbC"},
+		{"https://drive.google.com/drive/my-drive", ""},
+	}
+	for _, tt := range tests {
+		if got := ExtractFileID(tt.url); got != tt.want {
+			t.Errorf("ExtractFileID(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestIsFolderURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://drive.google.com/drive/folders/1Fold", true},
+		{"https://drive.google.com/drive/folders/1Fold?usp=sharing", true},
+		{"https://drive.google.com/drive/u/1/folders/1Fold", true},
+		{"https://drive.google.com/drive/folders/1Fold?usp=drivesdk", true},
+		{"https://drive.google.com/file/d/1AbC/view", false},
+		{"https://drive.google.com/open?id=1AbC", false},
+		{"https://drive.google.com/uc?export=view&id=1AbC", false},
+		{"https://drive.google.com/drive/my-drive#id=1AbC", false},
+		{"1AbC", false},
+	}
+	for _, tt := range tests {
+		if got := IsFolderURL(tt.url); got != tt.want {
+			t.Errorf("IsFolderURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package gget
+
+import (
+	"encoding/base64"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+var driveHosts = []string{"drive.google.com", "docs.google.com", "script.google.com", "drive.usercontent.google.com", "www.googleapis.com"}
+
+// otherHost returns the URL to download directly for a link that is not
+// a Google Drive one: the rewritten link for Dropbox, OneDrive and
+// SharePoint, or any other http(s) URL as it is, like wget would. With
+// DriveOnly set, every link is treated as a Drive link.
+func (g *Client) otherHost(urlStr string) (string, bool) {
+	if g.DriveOnly {
+		return "", false
+	}
+	if direct, ok := directURL(urlStr); ok {
+		return direct, true
+	}
+	u, err := url.Parse(urlStr)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	if slices.Contains(driveHosts, strings.ToLower(u.Hostname())) {
+		return "", false
+	}
+	return urlStr, true
+}
+
+// directURL rewrites a shared link from a host other than Google Drive
+// into a URL that serves the file itself, reporting false for Drive links
+// and anything it does not recognize.
+func directURL(urlStr string) (string, bool) {
+	u, err := url.Parse(urlStr)
+	if err != nil || u.Scheme != "https" {
+		return "", false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "dropbox.com" || host == "www.dropbox.com":
+		return dropboxURL(u)
+	case host == "1drv.ms" || host == "onedrive.live.com":
+		return oneDriveURL(urlStr), true
+	case strings.HasSuffix(host, ".sharepoint.com"):
+		return sharePointURL(u)
+	}
+	return "", false
+}
+
+// dropboxURL turns a Dropbox shared link, either /s/... or /scl/fi/...
+// (with its rlkey), into a direct download by setting dl=1. Shared
+// folders (/scl/fo/... or /sh/...) download as a zip archive.
+func dropboxURL(u *url.URL) (string, bool) {
+	if !strings.HasPrefix(u.Path, "/s/") && !strings.HasPrefix(u.Path, "/scl/") && !strings.HasPrefix(u.Path, "/sh/") {
+		return "", false
+	}
+	query := u.Query()
+	query.Del("raw")
+	query.Set("dl", "1")
+	u.RawQuery = query.Encode()
+	u.Fragment = ""
+	return u.String(), true
+}
+
+// oneDriveURL maps a personal OneDrive sharing link, including 1drv.ms
+// short links, to the content of the shared item through the OneDrive
+// shares API, which takes the link itself encoded as the share ID.
+func oneDriveURL(urlStr string) string {
+	shareID := "u!" + base64.RawURLEncoding.EncodeToString([]byte(urlStr))
+	return "https://api.onedrive.com/v1.0/shares/" + shareID + "/root/content"
+}
+
+// sharePointURL turns a SharePoint or OneDrive for Business sharing link,
+// whose path starts with a type marker such as /:u:/ or /:x:/, into a
+// direct download by adding download=1.
+func sharePointURL(u *url.URL) (string, bool) {
+	if !strings.HasPrefix(u.Path, "/:") {
+		return "", false
+	}
+	query := u.Query()
+	query.Set("download", "1")
+	u.RawQuery = query.Encode()
+	u.Fragment = ""
+	return u.String(), true
+}
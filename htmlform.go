@@ -0,0 +1,53 @@
+package gget
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// parsedConfirmationForm locates the download form of a confirmation page
+// in its parsed HTML tree, so attribute order and markup details do not
+// matter, and returns the URL it submits to.
+func parsedConfirmationForm(contents string, base *url.URL) (string, bool) {
+	doc, err := html.Parse(strings.NewReader(contents))
+	if err != nil {
+		return "", false
+	}
+
+	for form := range doc.Descendants() {
+		if form.Type != html.ElementNode || form.DataAtom != atom.Form {
+			continue
+		}
+		attrs := nodeAttrs(form)
+		if attrs["id"] != "download-form" && !strings.Contains(attrs["action"], "download") {
+			continue
+		}
+
+		fields := url.Values{}
+		for input := range form.Descendants() {
+			if input.Type != html.ElementNode || input.DataAtom != atom.Input {
+				continue
+			}
+			inputAttrs := nodeAttrs(input)
+			if name := inputAttrs["name"]; name != "" {
+				fields.Set(name, inputAttrs["value"])
+			}
+		}
+		if formURL, ok := submitURL(attrs["action"], fields, base); ok {
+			return formURL, true
+		}
+	}
+	return "", false
+}
+
+// nodeAttrs returns the attributes of an element, keyed by lowercase name.
+func nodeAttrs(n *html.Node) map[string]string {
+	attrs := make(map[string]string, len(n.Attr))
+	for _, attr := range n.Attr {
+		attrs[attr.Key] = attr.Val
+	}
+	return attrs
+}
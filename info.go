@@ -0,0 +1,113 @@
+package gget
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// FileInfo describes a remote file without downloading it. Fields the
+// server does not report are left empty; Size is -1 when unknown.
+type FileInfo struct {
+	ID       string    `json:"id,omitempty"`
+	Name     string    `json:"name"`
+	Path     string    `json:"path,omitempty"` // relative to the listed folder
+	Size     int64     `json:"size"`
+	MimeType string    `json:"mimeType,omitempty"`
+	MD5      string    `json:"md5,omitempty"`
+	Owner    string    `json:"owner,omitempty"`
+	Modified time.Time `json:"modified,omitzero"`
+	Trashed  bool      `json:"trashed,omitempty"`
+
+	rawPath string // Path with the names as they are in Drive
+}
+
+// Info looks up the metadata of urlStr through the Drive API when
+// authenticated, or otherwise with a HEAD request for the resolved
+// download URL (or for urlStr itself with opts.DirectMedia).
+func (g *Client) Info(ctx context.Context, urlStr string, opts Options) (*FileInfo, error) {
+	if direct, ok := g.otherHost(urlStr); ok && !opts.DirectMedia {
+		urlStr, opts.DirectMedia = direct, true
+	}
+	fileID := ""
+	downloadURL := urlStr
+	if !opts.DirectMedia {
+		if fileID = ExtractFileID(urlStr); fileID == "" {
+			return nil, errNoFileID
+		}
+		g.rememberResourceKey(fileID, urlStr)
+
+		if g.Auth != nil {
+			meta, err := g.getMetadata(ctx, fileID)
+			if err != nil {
+				return nil, err
+			}
+			if meta, err = g.resolveShortcut(ctx, meta); err != nil {
+				return nil, err
+			}
+			return meta.info(), nil
+		}
+
+		var err error
+		if downloadURL, _, err = g.ResolveURL(ctx, urlStr); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := g.newRequest(ctx, downloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Method = http.MethodHead
+
+	resp, err := g.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode, fmt.Errorf("unexpected response status: %s", resp.Status))
+	}
+
+	defaultName := "download"
+	if fileID != "" {
+		defaultName = fmt.Sprintf("gdrive_%s", fileID)
+	}
+	info := &FileInfo{
+		ID:       fileID,
+		Name:     g.getFileName(resp, defaultName),
+		Size:     resp.ContentLength,
+		MimeType: resp.Header.Get("Content-Type"),
+		MD5:      headerMD5(resp),
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.Modified = modified
+	}
+	return info, nil
+}
+
+// IsFolder reports whether the item is a Drive folder.
+func (f *FileInfo) IsFolder() bool {
+	return f.MimeType == FOLDER_MIME_TYPE
+}
+
+// info converts Drive API metadata to a FileInfo.
+func (f *driveFile) info() *FileInfo {
+	info := &FileInfo{ID: f.ID, Name: f.Name, Size: -1, MimeType: f.MimeType, MD5: f.MD5, Trashed: f.Trashed}
+	if size, err := strconv.ParseInt(f.Size, 10, 64); err == nil {
+		info.Size = size
+	}
+	if len(f.Owners) > 0 {
+		info.Owner = f.Owners[0].DisplayName
+		if email := f.Owners[0].EmailAddress; email != "" {
+			info.Owner += " <" + email + ">"
+		}
+	}
+	if modified, err := time.Parse(time.RFC3339, f.Modified); err == nil {
+		info.Modified = modified
+	}
+	return info
+}
@@ -0,0 +1,44 @@
+package gget
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// The Keychain is driven through the security tool. Commands go to its
+// interactive mode on stdin so the token never shows up in a process
+// list, base64-encoded so it needs no quoting.
+
+func keyringGet() ([]byte, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", KEYRING_SERVICE, "-a", KEYRING_ACCOUNT, "-w").Output()
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok && exit.ExitCode() == 44 {
+			return nil, errNoKeyringItem
+		}
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+}
+
+func keyringSet(data []byte) error {
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		KEYRING_SERVICE, KEYRING_ACCOUNT, base64.StdEncoding.EncodeToString(data)))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil || stderr.Len() > 0 {
+		return fmt.Errorf("security: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func keyringDelete() error {
+	err := exec.Command("security", "delete-generic-password", "-s", KEYRING_SERVICE, "-a", KEYRING_ACCOUNT).Run()
+	if exit, ok := err.(*exec.ExitError); ok && exit.ExitCode() == 44 {
+		return errNoKeyringItem
+	}
+	return err
+}
@@ -0,0 +1,56 @@
+//go:build !darwin && !windows
+
+package gget
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// The Secret Service (GNOME Keyring, KWallet) is driven through
+// secret-tool, which reads the secret from stdin.
+
+func keyringGet() ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "lookup", "service", KEYRING_SERVICE, "account", KEYRING_ACCOUNT)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok && stderr.Len() == 0 {
+			// A lookup that finds nothing fails silently
+			return nil, errNoKeyringItem
+		}
+		return nil, secretToolError(err, &stderr)
+	}
+	return out, nil
+}
+
+func keyringSet(data []byte) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "store", "--label=gget OAuth token", "service", KEYRING_SERVICE, "account", KEYRING_ACCOUNT)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return secretToolError(err, &stderr)
+	}
+	return nil
+}
+
+func keyringDelete() error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "clear", "service", KEYRING_SERVICE, "account", KEYRING_ACCOUNT)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return secretToolError(err, &stderr)
+	}
+	return nil
+}
+
+func secretToolError(err error, stderr *bytes.Buffer) error {
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("secret-tool: %s", msg)
+	}
+	return fmt.Errorf("secret-tool: %v", err)
+}
@@ -0,0 +1,87 @@
+package gget
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// The Credential Manager keeps the token as a generic credential.
+
+const (
+	CRED_TYPE_GENERIC          = 1
+	CRED_PERSIST_LOCAL_MACHINE = 2
+	ERROR_NOT_FOUND            = 1168
+)
+
+var (
+	advapi32      = syscall.NewLazyDLL("advapi32.dll")
+	procCredRead  = advapi32.NewProc("CredReadW")
+	procCredWrite = advapi32.NewProc("CredWriteW")
+	procCredDel   = advapi32.NewProc("CredDeleteW")
+	procCredFree  = advapi32.NewProc("CredFree")
+)
+
+// credential is the Win32 CREDENTIALW structure.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+func keyringTarget() *uint16 {
+	target, _ := syscall.UTF16PtrFromString(KEYRING_SERVICE + ":" + KEYRING_ACCOUNT)
+	return target
+}
+
+// credError maps a failed call's error to errNoKeyringItem when the
+// credential does not exist.
+func credError(err error) error {
+	if errno, ok := err.(syscall.Errno); ok && errno == ERROR_NOT_FOUND {
+		return errNoKeyringItem
+	}
+	return err
+}
+
+func keyringGet() ([]byte, error) {
+	var cred *credential
+	ok, _, err := procCredRead.Call(uintptr(unsafe.Pointer(keyringTarget())), CRED_TYPE_GENERIC, 0, uintptr(unsafe.Pointer(&cred)))
+	if ok == 0 {
+		return nil, credError(err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	return append([]byte(nil), unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)...), nil
+}
+
+func keyringSet(data []byte) error {
+	user, _ := syscall.UTF16PtrFromString(KEYRING_ACCOUNT)
+	cred := credential{
+		Type:               CRED_TYPE_GENERIC,
+		TargetName:         keyringTarget(),
+		CredentialBlobSize: uint32(len(data)),
+		CredentialBlob:     unsafe.SliceData(data),
+		Persist:            CRED_PERSIST_LOCAL_MACHINE,
+		UserName:           user,
+	}
+	ok, _, err := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0)
+	if ok == 0 {
+		return err
+	}
+	return nil
+}
+
+func keyringDelete() error {
+	ok, _, err := procCredDel.Call(uintptr(unsafe.Pointer(keyringTarget())), CRED_TYPE_GENERIC, 0)
+	if ok == 0 {
+		return credError(err)
+	}
+	return nil
+}
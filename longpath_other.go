@@ -0,0 +1,8 @@
+//go:build !windows
+
+package gget
+
+// longPath is only needed for the Windows path length limit.
+func longPath(path string) string {
+	return path
+}
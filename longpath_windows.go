@@ -0,0 +1,29 @@
+package gget
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// MAX_SHORT_PATH is the longest path Windows APIs accept without the
+// \\?\ prefix: MAX_PATH (260) less room for an 8.3 file name, which is the
+// limit for directories.
+const MAX_SHORT_PATH = 247
+
+// longPath returns path in its \\?\ extended-length form when it is too
+// long for the usual Windows limit, so that deeply nested folders can
+// still be written. Shorter paths are returned unchanged.
+func longPath(path string) string {
+	if strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil || len(abs) <= MAX_SHORT_PATH {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		// \\server\share\... becomes \\?\UNC\server\share\...
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
@@ -0,0 +1,33 @@
+package gget
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLongPath(t *testing.T) {
+	short := `C:\Users\me\file.bin`
+	if got := longPath(short); got != short {
+		t.Errorf("longPath(%q) = %q, want it unchanged", short, got)
+	}
+
+	long := `C:\` + strings.Repeat(`folder\`, 40) + "file.bin"
+	if got, want := longPath(long), `\\?\`+long; got != want {
+		t.Errorf("longPath(%q) = %q, want %q", long, got, want)
+	}
+	if got := longPath(`\\?\` + long); got != `\\?\`+long {
+		t.Errorf("longPath of an extended-length path = %q, want it unchanged", got)
+	}
+
+	unc := `\\server\share\` + strings.Repeat(`folder\`, 40) + "file.bin"
+	if got, want := longPath(unc), `\\?\UNC\server\share\`+strings.Repeat(`folder\`, 40)+"file.bin"; got != want {
+		t.Errorf("longPath(%q) = %q, want %q", unc, got, want)
+	}
+
+	relative := strings.Repeat(`folder\`, 40) + "file.bin"
+	abs, _ := filepath.Abs(relative)
+	if got, want := longPath(relative), `\\?\`+abs; got != want {
+		t.Errorf("longPath(%q) = %q, want %q", relative, got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package gget
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileMetadata is the content of the file.info.json written with
+// WriteMetadata. Downloads made without the Drive API only know the
+// fields the download response carries.
+type fileMetadata struct {
+	ID          string          `json:"id,omitempty"`
+	Name        string          `json:"name"`
+	MimeType    string          `json:"mimeType,omitempty"`
+	Size        int64           `json:"size"`
+	MD5         string          `json:"md5Checksum,omitempty"`
+	Created     string          `json:"createdTime,omitempty"`
+	Modified    string          `json:"modifiedTime,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Owners      []metadataOwner `json:"owners,omitempty"`
+	URL         string          `json:"sourceUrl,omitempty"`
+	Downloaded  time.Time       `json:"downloadedTime"`
+}
+
+type metadataOwner struct {
+	Name  string `json:"displayName,omitempty"`
+	Email string `json:"emailAddress,omitempty"`
+}
+
+// writeMetadata saves the metadata of the finished file t.output as
+// t.output.info.json.
+func writeMetadata(t *transfer) error {
+	info, err := os.Stat(t.path())
+	if err != nil {
+		return classify(ErrDisk, fmt.Errorf("failed to stat downloaded file: %v", err))
+	}
+	meta := fileMetadata{
+		ID:         t.id,
+		Name:       filepath.Base(t.output),
+		MimeType:   t.contentType,
+		Size:       info.Size(),
+		MD5:        t.md5,
+		URL:        PublicURL(t.url),
+		Downloaded: time.Now().UTC(),
+	}
+	if !t.modified.IsZero() {
+		meta.Modified = t.modified.UTC().Format(time.RFC3339)
+	}
+	if f := t.meta; f != nil {
+		meta.Name, meta.MimeType = f.Name, f.MimeType
+		meta.Created, meta.Description = f.Created, f.Description
+		for _, owner := range f.Owners {
+			meta.Owners = append(meta.Owners, metadataOwner{owner.DisplayName, owner.EmailAddress})
+		}
+	}
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(t.path()+".info.json", append(data, '\n'), 0666); err != nil {
+		return classify(ErrDisk, fmt.Errorf("failed to write metadata file: %v", err))
+	}
+	return nil
+}
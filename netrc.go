@@ -0,0 +1,119 @@
+package gget
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// netrcLogin is the login and password of one machine in a .netrc file.
+type netrcLogin struct {
+	login    string
+	password string
+}
+
+// netrcFile holds the credentials read by LoadNetrc.
+type netrcFile struct {
+	machines map[string]netrcLogin // by host name
+	fallback *netrcLogin           // the default entry, if any
+}
+
+// LoadNetrc reads the machine credentials of a .netrc file, as curl and
+// wget use it, and sends them with HTTP basic authentication to those
+// hosts. They are only used for links other than Google Drive ones, and
+// not on requests that already carry an Authorization header.
+func (g *Client) LoadNetrc(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read netrc file: %v", err)
+	}
+
+	netrc := &netrcFile{machines: make(map[string]netrcLogin)}
+	var current *netrcLogin
+	var host string
+	save := func() {
+		if current == nil {
+			return
+		}
+		if host == "" {
+			netrc.fallback = current
+		} else if _, ok := netrc.machines[host]; !ok {
+			// The first entry for a host wins, as in curl
+			netrc.machines[host] = *current
+		}
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		fields := strings.Fields(lines[i])
+		for j := 0; j < len(fields); j++ {
+			token := fields[j]
+			if strings.HasPrefix(token, "#") {
+				break
+			}
+			value := ""
+			if j+1 < len(fields) {
+				value = fields[j+1]
+			}
+			switch token {
+			case "machine":
+				save()
+				current, host = &netrcLogin{}, strings.ToLower(value)
+				j++
+			case "default":
+				save()
+				current, host = &netrcLogin{}, ""
+			case "login", "password", "account":
+				if current == nil {
+					return fmt.Errorf("%s:%d: %s outside a machine entry", path, i+1, token)
+				}
+				if token == "login" {
+					current.login = value
+				} else if token == "password" {
+					current.password = value
+				}
+				j++
+			case "macdef":
+				// Macros run until the next blank line
+				for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+					i++
+				}
+				j = len(fields)
+			default:
+				return fmt.Errorf("%s:%d: unexpected %q", path, i+1, token)
+			}
+		}
+	}
+	save()
+
+	g.netrc = netrc
+	return nil
+}
+
+// netrcFor returns the .netrc credentials for u, or nil.
+func (g *Client) netrcFor(u *url.URL) *netrcLogin {
+	if g.netrc == nil || !isGenericURL(u) {
+		return nil
+	}
+	if login, ok := g.netrc.machines[strings.ToLower(u.Hostname())]; ok {
+		return &login
+	}
+	return g.netrc.fallback
+}
+
+// isGenericURL reports whether u is an http(s) URL outside the Google
+// Drive hosts and the googleusercontent.com hosts Drive downloads
+// redirect to, the only kind host credentials are sent to. Follow-up
+// requests to a redirected download are checked here too.
+func isGenericURL(u *url.URL) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "googleusercontent.com" || strings.HasSuffix(host, ".googleusercontent.com") {
+		return false
+	}
+	return !slices.Contains(driveHosts, host)
+}
@@ -0,0 +1,129 @@
+package gget
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// credentialServer serves Drive downloads that redirect to
+// googleusercontent.com, as Drive does, plus files on a generic host, and
+// records the Authorization header each host receives.
+type credentialServer struct {
+	data []byte
+	mu   sync.Mutex
+	auth map[string][]string // host to the Authorization headers it got
+}
+
+func (s *credentialServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.auth[r.Host] = append(s.auth[r.Host], r.Header.Get("Authorization"))
+	s.mu.Unlock()
+	if r.Host == "drive.google.com" {
+		http.Redirect(w, r, "https://doc-0s-docs.googleusercontent.com/download", http.StatusFound)
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="file.bin"`)
+	http.ServeContent(w, r, "file.bin", time.Unix(1700000000, 0), bytes.NewReader(s.data))
+}
+
+// newCredentialClient returns a client that sends every request to s and
+// holds both a -bearer style HostAuth and a default .netrc login.
+func newCredentialClient(t *testing.T, s *credentialServer) *Client {
+	g := newTestClient(t, s)
+	g.HostAuth = "Bearer secret"
+	netrc := filepath.Join(t.TempDir(), "netrc")
+	if err := os.WriteFile(netrc, []byte("default login user password pass\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.LoadNetrc(netrc); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func (s *credentialServer) check(t *testing.T) {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for host, headers := range s.auth {
+		for _, auth := range headers {
+			if auth != "" {
+				t.Errorf("%s received Authorization %q", host, auth)
+			}
+		}
+	}
+	if len(s.auth["doc-0s-docs.googleusercontent.com"]) < 2 {
+		t.Errorf("googleusercontent.com got %d requests, want the redirect and a follow-up", len(s.auth["doc-0s-docs.googleusercontent.com"]))
+	}
+}
+
+func TestNoCredentialsAfterDriveRedirectResume(t *testing.T) {
+	s := &credentialServer{data: bytes.Repeat([]byte("0123456789"), 10000), auth: make(map[string][]string)}
+	g := newCredentialClient(t, s)
+	g.Resume = true
+
+	output := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(output+".part", s.data[:len(s.data)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Download(context.Background(), "https://drive.google.com/file/d/abc/view", Options{Output: output}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(output); !bytes.Equal(got, s.data) {
+		t.Errorf("resumed file has %d bytes, want the %d served", len(got), len(s.data))
+	}
+	s.check(t)
+}
+
+func TestHostCredentialsOnGenericURL(t *testing.T) {
+	s := &credentialServer{data: []byte("hello"), auth: make(map[string][]string)}
+	g := newCredentialClient(t, s)
+
+	var out strings.Builder
+	ctx := withSink(context.Background(), &out)
+	if err := g.Download(ctx, "https://files.example.com/file.bin", Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.auth["files.example.com"]; len(got) != 1 || got[0] != "Bearer secret" {
+		t.Errorf("files.example.com received Authorization %q, want the HostAuth", got)
+	}
+}
+
+func TestNoCredentialsOnDriveSegments(t *testing.T) {
+	s := &credentialServer{data: bytes.Repeat([]byte("0123456789"), 100000), auth: make(map[string][]string)}
+	g := newCredentialClient(t, s)
+	g.Chunks = 4
+
+	output := filepath.Join(t.TempDir(), "file.bin")
+	if err := g.Download(context.Background(), "https://drive.google.com/file/d/abc/view", Options{Output: output}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(output); !bytes.Equal(got, s.data) {
+		t.Errorf("segmented file has %d bytes, want the %d served", len(got), len(s.data))
+	}
+	s.check(t)
+	if got := len(s.auth["doc-0s-docs.googleusercontent.com"]); got < 1+g.Chunks {
+		t.Errorf("googleusercontent.com got %d requests, want one per segment", got)
+	}
+}
+
+func TestNoCredentialsOnDriveRange(t *testing.T) {
+	s := &credentialServer{data: bytes.Repeat([]byte("0123456789"), 1000), auth: make(map[string][]string)}
+	g := newCredentialClient(t, s)
+
+	output := filepath.Join(t.TempDir(), "file.bin")
+	if err := g.Download(context.Background(), "https://drive.google.com/file/d/abc/view", Options{Output: output, Range: &ByteRange{Start: 10, End: 109}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(output); !bytes.Equal(got, s.data[10:110]) {
+		t.Errorf("range has %d bytes, want 100", len(got))
+	}
+	s.check(t)
+}
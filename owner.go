@@ -0,0 +1,71 @@
+package gget
+
+import (
+	"fmt"
+	"os/user"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Owner is the user and group finished files are handed to; -1 leaves
+// either one unchanged.
+type Owner struct {
+	UID int
+	GID int
+}
+
+// ParseOwner parses a chown-style "user", "user:group" or ":group", where
+// each part is a name or a numeric ID. A user given alone keeps the file's
+// group.
+func ParseOwner(spec string) (*Owner, error) {
+	if runtime.GOOS == "windows" {
+		return nil, fmt.Errorf("file ownership cannot be changed on Windows")
+	}
+	userName, groupName, _ := strings.Cut(spec, ":")
+	if userName == "" && groupName == "" {
+		return nil, fmt.Errorf("invalid owner %q", spec)
+	}
+
+	owner := &Owner{UID: -1, GID: -1}
+	if userName != "" {
+		id, err := lookupID(userName, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unknown user %q", userName)
+		}
+		owner.UID = id
+	}
+	if groupName != "" {
+		id, err := lookupID(groupName, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unknown group %q", groupName)
+		}
+		owner.GID = id
+	}
+	return owner, nil
+}
+
+// lookupID returns name as a numeric ID, looking it up with lookup unless
+// it already is one.
+func lookupID(name string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil && id >= 0 {
+		return id, nil
+	}
+	id, err := lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(id)
+}
@@ -0,0 +1,57 @@
+package gget
+
+import (
+	"context"
+	"sync"
+)
+
+// pauseGate holds transfers while the client is paused.
+type pauseGate struct {
+	mu      sync.Mutex
+	resumed chan struct{} // closed by Unpause; nil while running
+}
+
+// Pause holds every transfer of the client before its next read, keeping
+// connections and partial files as they are, until Unpause is called.
+// Servers may still drop a connection that stays idle for long; such
+// transfers resume from their offset like any other interrupted one.
+func (g *Client) Pause() {
+	g.pause.mu.Lock()
+	defer g.pause.mu.Unlock()
+	if g.pause.resumed == nil {
+		g.pause.resumed = make(chan struct{})
+	}
+}
+
+// Unpause lets transfers held by Pause continue.
+func (g *Client) Unpause() {
+	g.pause.mu.Lock()
+	defer g.pause.mu.Unlock()
+	if g.pause.resumed != nil {
+		close(g.pause.resumed)
+		g.pause.resumed = nil
+	}
+}
+
+// Paused reports whether Pause is in effect.
+func (g *Client) Paused() bool {
+	g.pause.mu.Lock()
+	defer g.pause.mu.Unlock()
+	return g.pause.resumed != nil
+}
+
+// waitPaused blocks while the client is paused.
+func (g *Client) waitPaused(ctx context.Context) error {
+	g.pause.mu.Lock()
+	resumed := g.pause.resumed
+	g.pause.mu.Unlock()
+	if resumed == nil {
+		return nil
+	}
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,286 @@
+package gget
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const PROGRESS_BAR_WIDTH = 30
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// progressBar renders the progress of one download. On a terminal it
+// redraws a bar with speed and ETA in place (or a spinner when the size is
+// unknown); otherwise it prints a plain status line every few seconds.
+type progressBar struct {
+	group    *progressGroup
+	name     string
+	verb     string // Downloading or Uploading, for plain status lines
+	out      io.Writer
+	quiet    bool
+	tty      bool
+	total    int64
+	interval time.Duration
+	// event, when set, reports progress as a JSON event instead of drawing
+	event func(current int64, speed float64)
+
+	start     time.Time
+	initial   int64
+	current   int64
+	lastDraw  time.Time
+	lastBytes int64
+	speed     float64 // exponentially smoothed bytes per second
+	frame     int
+}
+
+// ProgressRecord is one line of the progress stream written to
+// Client.ProgressJSON.
+type ProgressRecord struct {
+	Name  string  `json:"name"`
+	Bytes int64   `json:"bytes"`
+	Total int64   `json:"total"` // -1 if unknown
+	Speed int64   `json:"speed"` // bytes per second
+	ETA   float64 `json:"eta"`   // seconds remaining, -1 if unknown
+}
+
+// progressGroup tracks the active progress bars of one Client. While
+// several downloads run at once, a terminal shows a single aggregated line
+// for all of them and a line per finished file instead of each bar.
+type progressGroup struct {
+	mu       sync.Mutex
+	bars     map[*progressBar]bool
+	lastDraw time.Time
+	frame    int
+}
+
+// newProgress starts a progress display for t, a download of total bytes
+// (-1 if unknown) that already has initial bytes on disk. Callers must
+// close the bar when the transfer ends.
+func (g *Client) newProgress(t *transfer, total int64, initial int64) *progressBar {
+	bar := &progressBar{
+		group:     &g.progress,
+		name:      filepath.Base(t.output),
+		verb:      "Downloading",
+		out:       g.Log,
+		quiet:     g.Quiet,
+		tty:       isTerminal(g.Log),
+		total:     total,
+		start:     time.Now(),
+		initial:   initial,
+		current:   initial,
+		lastDraw:  time.Now(),
+		lastBytes: initial,
+		interval:  100 * time.Millisecond,
+	}
+	if !bar.tty {
+		bar.interval = 5 * time.Second
+	}
+	if g.Events != nil || g.ProgressJSON != nil || t.onProgress != nil {
+		bar.quiet = false
+		bar.interval = time.Second
+		if t.onProgress != nil {
+			bar.interval = 200 * time.Millisecond
+		}
+		bar.event = func(current int64, speed float64) {
+			g.emit(Event{Type: "progress", URL: t.url, ID: t.id, Name: t.output, Size: max(total, 0), Bytes: current, Speed: int64(speed)})
+			g.writeProgress(t.output, current, total, speed)
+			if t.onProgress != nil {
+				t.onProgress(current, total, speed)
+			}
+		}
+	}
+
+	g.progress.mu.Lock()
+	defer g.progress.mu.Unlock()
+	if g.progress.bars == nil {
+		g.progress.bars = make(map[*progressBar]bool)
+	}
+	g.progress.bars[bar] = true
+	return bar
+}
+
+// writeProgress writes a ProgressRecord line to ProgressJSON.
+func (g *Client) writeProgress(name string, current, total int64, speed float64) {
+	if g.ProgressJSON == nil {
+		return
+	}
+	record := ProgressRecord{Name: name, Bytes: current, Total: total, Speed: int64(speed), ETA: -1}
+	if total > 0 && current >= total {
+		record.ETA = 0
+	} else if total > 0 && speed > 0 {
+		record.ETA = math.Round(float64(total-current)/speed*10) / 10
+	}
+	data, _ := json.Marshal(record)
+	g.eventsMu.Lock()
+	defer g.eventsMu.Unlock()
+	g.ProgressJSON.Write(append(data, '\n'))
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// update records that current bytes have been written, redrawing at most
+// once per interval.
+func (p *progressBar) update(current int64) {
+	if p.quiet {
+		return
+	}
+	p.group.mu.Lock()
+	defer p.group.mu.Unlock()
+
+	p.current = current
+	now := time.Now()
+	elapsed := now.Sub(p.lastDraw)
+	if elapsed < p.interval {
+		return
+	}
+
+	instant := float64(current-p.lastBytes) / elapsed.Seconds()
+	if p.speed == 0 {
+		p.speed = instant
+	} else {
+		p.speed = 0.3*instant + 0.7*p.speed
+	}
+	p.lastDraw = now
+	p.lastBytes = current
+
+	if p.tty && p.event == nil && len(p.group.bars) > 1 {
+		p.group.draw(p.out, p.interval)
+		return
+	}
+	p.draw(current)
+}
+
+// finish draws the final state, with the average speed of the whole
+// transfer, and ends the progress line.
+func (p *progressBar) finish(current int64) {
+	if p.quiet {
+		return
+	}
+	p.group.mu.Lock()
+	defer p.group.mu.Unlock()
+
+	p.current = current
+	if elapsed := time.Since(p.start).Seconds(); elapsed > 0 {
+		p.speed = float64(current-p.initial) / elapsed
+	}
+
+	if p.tty && p.event == nil && len(p.group.bars) > 1 {
+		// Print the finished file above the aggregated line
+		line := fmt.Sprintf("%s: %s  %s/s", p.name, FormatSize(current), FormatSize(int64(p.speed)))
+		fmt.Fprintf(p.out, "\r%-80s\n", line)
+		return
+	}
+	p.draw(current)
+	if p.tty && p.event == nil {
+		fmt.Fprintln(p.out)
+	}
+}
+
+// close removes the bar from its group once the transfer has ended,
+// whether or not it completed.
+func (p *progressBar) close() {
+	p.group.mu.Lock()
+	defer p.group.mu.Unlock()
+	delete(p.group.bars, p)
+}
+
+func (p *progressBar) draw(current int64) {
+	if p.event != nil {
+		p.event(current, p.speed)
+		return
+	}
+
+	line := progressLine(current, p.total, p.speed, p.tty, &p.frame)
+	if p.tty {
+		// Pad to clear leftovers from a longer previous line
+		fmt.Fprintf(p.out, "\r%-80s", line)
+	} else if len(p.group.bars) > 1 {
+		fmt.Fprintf(p.out, "%s %s... %s\n", p.verb, p.name, line)
+	} else {
+		fmt.Fprintf(p.out, "%s... %s\n", p.verb, line)
+	}
+}
+
+// draw renders one line summing up every active bar. The caller holds
+// the group's lock.
+func (pg *progressGroup) draw(out io.Writer, interval time.Duration) {
+	if time.Since(pg.lastDraw) < interval {
+		return
+	}
+	pg.lastDraw = time.Now()
+
+	var current, total int64
+	var speed float64
+	for bar := range pg.bars {
+		current += bar.current
+		speed += bar.speed
+		if bar.total > 0 && total >= 0 {
+			total += bar.total
+		} else {
+			total = -1
+		}
+	}
+
+	line := progressLine(current, total, speed, true, &pg.frame)
+	fmt.Fprintf(out, "\r%-80s", fmt.Sprintf("%d files %s", len(pg.bars), line))
+}
+
+// progressLine formats a progress status: a bar with percentage, sizes,
+// speed and ETA when total is known, or a spinner with the byte count and
+// speed otherwise. Bars and spinners are only drawn on a terminal.
+func progressLine(current, total int64, speed float64, tty bool, frame *int) string {
+	if total <= 0 {
+		line := fmt.Sprintf("%s  %s/s", FormatSize(current), FormatSize(int64(speed)))
+		if tty {
+			line = spinnerFrames[*frame%len(spinnerFrames)] + " " + line
+			*frame++
+		}
+		return line
+	}
+
+	fraction := min(float64(current)/float64(total), 1)
+	line := fmt.Sprintf("%5.1f%%  %s / %s  %s/s", fraction*100, FormatSize(current), FormatSize(total), FormatSize(int64(speed)))
+	if speed > 0 && current < total {
+		eta := time.Duration(float64(total-current) / speed * float64(time.Second))
+		line += "  ETA " + eta.Round(time.Second).String()
+	}
+	if tty {
+		filled := int(fraction * PROGRESS_BAR_WIDTH)
+		bar := strings.Repeat("=", filled)
+		if filled < PROGRESS_BAR_WIDTH {
+			bar += ">" + strings.Repeat(" ", PROGRESS_BAR_WIDTH-filled-1)
+		}
+		line = "[" + bar + "] " + line
+	}
+	return line
+}
+
+// FormatSize renders a byte count with a binary unit suffix, e.g.
+// "12.3 MiB", the inverse of ParseSize.
+func FormatSize(n int64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n)
+	for _, unit := range []string{"KiB", "MiB", "GiB", "TiB"} {
+		value /= 1024
+		if value < 1024 || unit == "TiB" {
+			return fmt.Sprintf("%.1f %s", value, unit)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,197 @@
+package gget
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// PushResult counts what PushFolder changed.
+type PushResult struct {
+	Uploaded  int // new files
+	Updated   int // files whose content changed
+	Deleted   int // remote files moved to the trash
+	Unchanged int
+}
+
+// PushFolder mirrors the local directory dir into the Drive folder at
+// urlStr: new files are uploaded, files whose size or MD5 differ replace
+// the remote content, and subfolders are created as needed. With
+// deleteExtra, remote files missing locally are moved to the trash. Google
+// Docs, Sheets and Slides and shortcuts in the folder are left alone, as
+// are files excluded by SetFilters. With DryRun the changes are only
+// printed.
+func (g *Client) PushFolder(ctx context.Context, dir string, urlStr string, deleteExtra bool) (PushResult, error) {
+	var result PushResult
+	if g.Auth == nil {
+		return result, classify(ErrPermissionDenied, errNeedsAuth)
+	}
+	folderID := ExtractFileID(urlStr)
+	if folderID == "" {
+		return result, classify(ErrInvalidURL, fmt.Errorf("could not extract folder ID from URL"))
+	}
+
+	items, err := g.listFolder(ctx, folderID, "", true, false, map[string]bool{folderID: true})
+	if err != nil {
+		return result, err
+	}
+	// Local paths are compared with the remote names as they are, not
+	// as a download would have sanitized them
+	folders := map[string]string{"": folderID}
+	remote := make(map[string]*FileInfo)
+	for _, item := range items {
+		rel := g.normalizeName(item.rawPath)
+		if _, ok := folders[rel]; (ok || remote[rel] != nil) && !item.Trashed {
+			return result, fmt.Errorf("several remote items are at %s; rename or remove all but one of them first", rel)
+		}
+		switch {
+		case item.Trashed:
+		case item.IsFolder():
+			folders[rel] = item.ID
+		case item.MimeType != SHORTCUT_MIME_TYPE && nativeKinds[item.MimeType] == "":
+			remote[rel] = item
+		}
+	}
+
+	seen := make(map[string]bool)
+	err = filepath.WalkDir(dir, func(localPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return classify(ErrDisk, err)
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, localPath)
+		rel = g.normalizeName(filepath.ToSlash(rel))
+		if !g.wanted(rel) {
+			return nil
+		}
+		seen[rel] = true
+
+		item := remote[rel]
+		if item != nil {
+			same, err := sameContent(localPath, item)
+			if err != nil {
+				return err
+			}
+			if same {
+				result.Unchanged++
+				return nil
+			}
+		}
+
+		if item == nil {
+			g.logPush("upload", rel)
+		} else {
+			g.logPush("update", rel)
+		}
+		if g.DryRun {
+			return nil
+		}
+
+		if item != nil {
+			_, err = g.upload(ctx, localPath, "", item.ID)
+			result.Updated++
+		} else {
+			var parentID string
+			if parentID, err = g.ensureFolder(ctx, folders, path.Dir(rel)); err == nil {
+				_, err = g.upload(ctx, localPath, parentID, "")
+				result.Uploaded++
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", rel, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if deleteExtra {
+		for rel, item := range remote {
+			if seen[rel] || !g.wanted(rel) {
+				continue
+			}
+			g.logPush("delete", rel)
+			if g.DryRun {
+				continue
+			}
+			if err := g.trash(ctx, item.ID); err != nil {
+				return result, fmt.Errorf("%s: %w", rel, err)
+			}
+			result.Deleted++
+		}
+	}
+	return result, nil
+}
+
+var pushActions = map[string]string{"upload": "Uploading", "update": "Updating", "delete": "Deleting"}
+
+// logPush reports an upload, update or delete, or with DryRun that it
+// would be done.
+func (g *Client) logPush(action string, rel string) {
+	switch {
+	case g.DryRun:
+		fmt.Fprintf(g.Log, "Would %s %s\n", action, rel)
+	case !g.Quiet:
+		fmt.Fprintf(g.Log, "%s %s\n", pushActions[action], rel)
+	}
+}
+
+// sameContent reports whether the local file matches the remote item's
+// size and MD5.
+func sameContent(localPath string, item *FileInfo) (bool, error) {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return false, classify(ErrDisk, err)
+	}
+	if item.Size >= 0 && info.Size() != item.Size {
+		return false, nil
+	}
+	if item.MD5 == "" {
+		return false, nil
+	}
+	sum, err := fileMD5(localPath)
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(sum, item.MD5), nil
+}
+
+// ensureFolder returns the ID of the remote folder at rel, creating it and
+// its parents as needed. folders maps the paths of known folders to IDs.
+func (g *Client) ensureFolder(ctx context.Context, folders map[string]string, rel string) (string, error) {
+	if rel == "." {
+		rel = ""
+	}
+	if id, ok := folders[rel]; ok {
+		return id, nil
+	}
+
+	parentID, err := g.ensureFolder(ctx, folders, path.Dir(rel))
+	if err != nil {
+		return "", err
+	}
+	var created driveFile
+	err = g.sendJSON(ctx, "POST", apiURL("/files", allDrives(url.Values{"fields": {"id"}})), map[string]interface{}{
+		"name":     path.Base(rel),
+		"mimeType": FOLDER_MIME_TYPE,
+		"parents":  []string{parentID},
+	}, &created)
+	if err != nil {
+		return "", fmt.Errorf("failed to create folder %s: %w", rel, err)
+	}
+	folders[rel] = created.ID
+	return created.ID, nil
+}
+
+// trash moves fileID to the Drive trash.
+func (g *Client) trash(ctx context.Context, fileID string) error {
+	return g.sendJSON(ctx, "PATCH", apiURL("/files/"+url.PathEscape(fileID), allDrives(url.Values{})), map[string]bool{"trashed": true}, nil)
+}
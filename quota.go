@@ -0,0 +1,46 @@
+package gget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const QUOTA_POLL_INTERVAL = 15 * time.Minute
+
+// ErrQuotaExceeded is returned when Drive refuses a file because too many
+// users have downloaded it recently. Google lifts the limit within 24
+// hours.
+var ErrQuotaExceeded = errors.New("download quota exceeded: too many users have viewed or downloaded this file recently; Google resets the quota within 24 hours (see -retry-quota)")
+
+// isQuotaPage reports whether an HTML page from Drive is the "too many
+// users" quota notice rather than a confirmation page.
+func isQuotaPage(contents string) bool {
+	return strings.Contains(contents, "Too many users have viewed or downloaded this file recently") ||
+		strings.Contains(contents, "download quota for this file has been exceeded")
+}
+
+// retryQuota runs download, and while it fails with ErrQuotaExceeded polls
+// it again every QUOTA_POLL_INTERVAL until g.QuotaWait has passed.
+func (g *Client) retryQuota(ctx context.Context, download func() error) error {
+	deadline := time.Now().Add(g.QuotaWait)
+	for {
+		err := download()
+		if !errors.Is(err, ErrQuotaExceeded) || g.QuotaWait <= 0 {
+			return err
+		}
+
+		wait := min(QUOTA_POLL_INTERVAL, time.Until(deadline))
+		if wait <= 0 {
+			return fmt.Errorf("%w; still exceeded after waiting %v", err, g.QuotaWait)
+		}
+		if !g.Quiet {
+			fmt.Fprintf(g.Log, "Download quota exceeded; checking again in %v (giving up at %s)\n", wait.Round(time.Second), deadline.Format("15:04"))
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
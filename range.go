@@ -0,0 +1,41 @@
+package gget
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ByteRange is an inclusive slice of a file's bytes. End is -1 for the
+// rest of the file.
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// ParseByteRange parses "start-end" (both inclusive, as in an HTTP Range
+// header) or "start-" for everything from start on.
+func ParseByteRange(s string) (*ByteRange, error) {
+	first, last, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid range %q (want start-end)", s)
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return nil, fmt.Errorf("invalid range start %q", first)
+	}
+	r := &ByteRange{Start: start, End: -1}
+	if last != "" {
+		if r.End, err = strconv.ParseInt(last, 10, 64); err != nil || r.End < start {
+			return nil, fmt.Errorf("invalid range end %q", last)
+		}
+	}
+	return r, nil
+}
+
+func (r *ByteRange) String() string {
+	if r.End < 0 {
+		return fmt.Sprintf("%d-", r.Start)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
@@ -0,0 +1,157 @@
+package gget
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket shared by every reader of a download. It
+// refills at rate bytes per second and holds at most one second of burst.
+// A rate of zero, from the limit or the schedule, means no limit.
+type rateLimiter struct {
+	mu       sync.Mutex
+	rate     float64
+	tokens   float64
+	last     time.Time
+	schedule []rateWindow // overrides rate at the times it covers
+}
+
+// rateWindow applies rate from start up to end, in minutes after
+// midnight local time. A window with end before start wraps past
+// midnight, and one with end equal to start covers the whole day.
+type rateWindow struct {
+	start, end int
+	rate       float64
+}
+
+func (w rateWindow) covers(minute int) bool {
+	switch {
+	case w.start < w.end:
+		return minute >= w.start && minute < w.end
+	case w.start > w.end:
+		return minute >= w.start || minute < w.end
+	}
+	return true
+}
+
+func newRateLimiter(rate int64) *rateLimiter {
+	return &rateLimiter{rate: float64(rate), tokens: float64(rate), last: time.Now()}
+}
+
+// wait blocks until n more bytes may be transferred or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	rate := l.rateAt(now)
+	if rate <= 0 {
+		l.tokens, l.last = 0, now
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+	l.tokens = min(rate, l.tokens+now.Sub(l.last).Seconds()*rate)
+	l.last = now
+	l.tokens -= float64(n)
+
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	return sleep(ctx, delay)
+}
+
+// rateAt returns the limit in effect at now: that of the first schedule
+// window covering it, or otherwise the plain rate.
+func (l *rateLimiter) rateAt(now time.Time) float64 {
+	minute := now.Hour()*60 + now.Minute()
+	for _, w := range l.schedule {
+		if w.covers(minute) {
+			return w.rate
+		}
+	}
+	return l.rate
+}
+
+// SetRateSchedule varies the rate limit with the local time of day, from
+// a comma-separated list of "HH:MM-HH:MM=rate" windows such as
+// "08:00-18:00=1M,18:00-08:00=0". Rates take the suffixes of ParseSize,
+// and 0 means unlimited. Outside every window the SetRateLimit rate, if
+// any, applies.
+func (g *Client) SetRateSchedule(spec string) error {
+	var schedule []rateWindow
+	for _, part := range strings.Split(spec, ",") {
+		span, rateStr, ok := strings.Cut(strings.TrimSpace(part), "=")
+		from, to, ok2 := strings.Cut(span, "-")
+		if !ok || !ok2 {
+			return fmt.Errorf("invalid schedule entry %q (want HH:MM-HH:MM=rate)", part)
+		}
+		start, err := parseClock(from)
+		if err != nil {
+			return err
+		}
+		end, err := parseClock(to)
+		if err != nil {
+			return err
+		}
+		rate, err := ParseSize(rateStr)
+		if err != nil || rate < 0 {
+			return fmt.Errorf("invalid rate %q in schedule", rateStr)
+		}
+		schedule = append(schedule, rateWindow{start: start, end: end, rate: float64(rate)})
+	}
+
+	if g.limiter == nil {
+		g.limiter = newRateLimiter(0)
+	}
+	g.limiter.mu.Lock()
+	g.limiter.schedule = schedule
+	g.limiter.mu.Unlock()
+	return nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// sleep pauses for d, returning early with ctx's error if it is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WaitBetween sleeps for Wait, or with RandomWait for a random time
+// between half and one and a half times Wait, as wget's -w and
+// -random-wait do. Folder downloads call it between files; batch loops
+// should do the same between entries. Concurrent callers take turns, so
+// the files of parallel workers also start at least one wait apart.
+func (g *Client) WaitBetween(ctx context.Context) error {
+	if g.Wait <= 0 {
+		return ctx.Err()
+	}
+	g.waitMu.Lock()
+	defer g.waitMu.Unlock()
+	wait := g.Wait
+	if g.RandomWait {
+		wait = wait/2 + time.Duration(rand.Int63n(int64(wait)+1))
+	}
+	return sleep(ctx, wait)
+}
@@ -0,0 +1,57 @@
+package gget
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ResolveURL performs the confirmation steps for urlStr without
+// downloading anything and returns the direct URL of the file, together
+// with the headers (cookies or authorization) another client such as curl
+// or aria2 needs to fetch it.
+func (g *Client) ResolveURL(ctx context.Context, urlStr string) (string, http.Header, error) {
+	ref, err := g.Resolve(ctx, urlStr)
+	if err != nil {
+		return "", nil, err
+	}
+	return ref.URL, ref.Header, nil
+}
+
+// resolvedHeaders returns the credentials gget would send to urlStr.
+func (g *Client) resolvedHeaders(ctx context.Context, urlStr string) http.Header {
+	header := http.Header{}
+	req, err := g.newRequest(ctx, urlStr)
+	if err != nil {
+		return header
+	}
+	for _, name := range []string{"Authorization", "X-Goog-Drive-Resource-Keys"} {
+		if value := req.Header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+
+	var cookies []string
+	for _, cookie := range g.client.Jar.Cookies(req.URL) {
+		cookies = append(cookies, cookie.Name+"="+cookie.Value)
+	}
+	if len(cookies) > 0 {
+		header.Set("Cookie", strings.Join(cookies, "; "))
+	}
+	return header
+}
+
+// printURL writes the URL r resolves urlStr to on stdout, followed by any
+// headers needed to fetch it, one "Name: value" per line.
+func (g *Client) printURL(ctx context.Context, r Resolver, urlStr string) error {
+	ref, err := r.Resolve(ctx, urlStr)
+	if err != nil {
+		return err
+	}
+	fmt.Println(ref.URL)
+	for name := range ref.Header {
+		fmt.Printf("%s: %s\n", name, ref.Header.Get(name))
+	}
+	return nil
+}
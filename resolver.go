@@ -0,0 +1,227 @@
+package gget
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// FileRef is where a Resolver found the content of a shared link.
+type FileRef struct {
+	// URL serves the file itself with a plain GET.
+	URL string
+	// Header holds any extra request headers URL needs, such as cookies
+	// or an authorization.
+	Header http.Header
+	// Name is the file name to save under when no output is given; when
+	// empty the response's Content-Disposition or URL decides.
+	Name string
+	// MD5 is the file's hex MD5 checksum, if known, for Verify.
+	MD5 string
+}
+
+// Resolver turns the links of one host into the URL of their content.
+// Match reports whether the resolver handles urlStr at all; Resolve does
+// any lookups or confirmation steps needed.
+type Resolver interface {
+	Match(urlStr string) bool
+	Resolve(ctx context.Context, urlStr string) (*FileRef, error)
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   []Resolver
+)
+
+// RegisterResolver adds r to the resolvers every Client consults, in the
+// order they were registered and before the built-in ones for Drive and
+// the other hosts gget knows, so it can also take over links those would
+// handle. It is meant to be called from an init function.
+func RegisterResolver(r Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers = append(resolvers, r)
+}
+
+// registeredResolver returns the first registered resolver matching
+// urlStr, or nil.
+func registeredResolver(urlStr string) Resolver {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	for _, r := range resolvers {
+		if r.Match(urlStr) {
+			return r
+		}
+	}
+	return nil
+}
+
+// Resolve finds the content of urlStr with the first resolver that
+// matches it: a registered one, the one for Dropbox, OneDrive, SharePoint
+// and other non-Drive http(s) URLs, or the Drive resolver, which takes
+// everything else.
+func (g *Client) Resolve(ctx context.Context, urlStr string) (*FileRef, error) {
+	r := g.resolverFor(urlStr, false)
+	if r == nil {
+		return nil, errNoFileID
+	}
+	return r.Resolve(ctx, urlStr)
+}
+
+// resolverFor returns the resolver Resolve would use for urlStr, or nil
+// if none matches. direct selects the one that takes urlStr as-is.
+func (g *Client) resolverFor(urlStr string, direct bool) Resolver {
+	if direct {
+		return directResolver{}
+	}
+	if r := registeredResolver(urlStr); r != nil {
+		return r
+	}
+	for _, r := range []Resolver{hostResolver{g}, driveResolver{g}} {
+		if r.Match(urlStr) {
+			return r
+		}
+	}
+	return nil
+}
+
+// fileDownloader is implemented by the resolvers that download files
+// themselves instead of with a GET of the resolved FileRef.
+type fileDownloader interface {
+	download(ctx context.Context, urlStr string, output string) error
+}
+
+// directResolver takes an already-resolved media URL, such as a signed
+// googleapis.com link, as-is, skipping all Drive ID and confirmation
+// handling.
+type directResolver struct{}
+
+func (directResolver) Match(urlStr string) bool {
+	return true
+}
+
+func (directResolver) Resolve(ctx context.Context, urlStr string) (*FileRef, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, classify(ErrInvalidURL, fmt.Errorf("direct media URL must be an absolute https URL"))
+	}
+	return &FileRef{URL: urlStr, Header: http.Header{}}, nil
+}
+
+// hostResolver handles the links otherHost accepts.
+type hostResolver struct{ g *Client }
+
+func (r hostResolver) Match(urlStr string) bool {
+	_, ok := r.g.otherHost(urlStr)
+	return ok
+}
+
+func (r hostResolver) Resolve(ctx context.Context, urlStr string) (*FileRef, error) {
+	direct, _ := r.g.otherHost(urlStr)
+	return &FileRef{URL: direct, Header: http.Header{}}, nil
+}
+
+// driveResolver runs the Drive confirmation steps, or looks files up
+// through the Drive API when authenticated.
+type driveResolver struct{ g *Client }
+
+func (r driveResolver) Match(urlStr string) bool {
+	return ExtractFileID(urlStr) != ""
+}
+
+func (r driveResolver) Resolve(ctx context.Context, urlStr string) (*FileRef, error) {
+	g := r.g
+	fileID := ExtractFileID(urlStr)
+	if fileID == "" {
+		return nil, errNoFileID
+	}
+	g.rememberResourceKey(fileID, urlStr)
+
+	ref := &FileRef{}
+	switch kind := documentKind(urlStr); {
+	case g.Auth != nil:
+		meta, err := g.getMetadata(ctx, fileID)
+		if err != nil {
+			return nil, err
+		}
+		if meta, err = g.resolveShortcut(ctx, meta); err != nil {
+			return nil, err
+		}
+		ref.URL, ref.Name, ref.MD5 = mediaURL(meta.ID), meta.Name, meta.MD5
+		if kind := nativeKinds[meta.MimeType]; kind != "" {
+			format, mimeType, err := g.exportFormatFor(kind)
+			if err != nil {
+				return nil, err
+			}
+			ref.URL, ref.Name, ref.MD5 = apiExportURL(meta.ID, mimeType), withExtension(meta.Name, format), ""
+		}
+	case kind != "":
+		format, _, err := g.exportFormatFor(kind)
+		if err != nil {
+			return nil, err
+		}
+		ref.URL = g.withResourceKey(exportURL(kind, fileID, format), fileID)
+	default:
+		downloadURL, resp, err := g.resolveFile(ctx, fileID)
+		if err != nil {
+			return nil, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		ref.URL = downloadURL
+	}
+
+	ref.Header = g.resolvedHeaders(ctx, ref.URL)
+	return ref, nil
+}
+
+// download runs the confirmation steps and fetches the file in one go,
+// with segmented and ranged downloads.
+func (r driveResolver) download(ctx context.Context, urlStr string, output string) error {
+	return r.g.downloadFile(ctx, urlStr, output)
+}
+
+// downloadResolved downloads urlStr through r.
+func (g *Client) downloadResolved(ctx context.Context, r Resolver, urlStr string, output string) error {
+	if d, ok := r.(fileDownloader); ok {
+		return d.download(ctx, urlStr, output)
+	}
+	ref, err := r.Resolve(ctx, urlStr)
+	if err != nil {
+		return err
+	}
+
+	req, err := g.newRequest(ctx, ref.URL)
+	if err != nil {
+		return fmt.Errorf("failed to create download request: %v", err)
+	}
+	for name, values := range ref.Header {
+		req.Header[name] = values
+	}
+
+	resp, err := g.do(req)
+	if err != nil {
+		return fmt.Errorf("download request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp.StatusCode, fmt.Errorf("unexpected response status: %s", resp.Status))
+	}
+
+	if output == "" {
+		if ref.Name != "" {
+			output = g.placeOutput(ctx, ref.Name, "", resp.Header.Get("Content-Type"))
+		} else {
+			output = g.outputName(ctx, resp, "", "download")
+		}
+	}
+	md5 := ref.MD5
+	if md5 == "" {
+		md5 = headerMD5(resp)
+	}
+	return g.saveResponse(ctx, resp, &transfer{output: output, md5: md5, url: urlStr})
+}
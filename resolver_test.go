@@ -0,0 +1,17 @@
+package gget
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDirectMediaNeedsHTTPS(t *testing.T) {
+	g := New()
+	for _, urlStr := range []string{"http://example.com/file.bin", "example.com/file.bin", "1AbC"} {
+		err := g.Download(context.Background(), urlStr, Options{DirectMedia: true})
+		if !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("Download(%q) with DirectMedia: %v, want ErrInvalidURL", urlStr, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package gget
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// rememberResourceKey records the resourcekey parameter of a legacy shared
+// link, without which Drive answers requests for fileID with a permission
+// error.
+func (g *Client) rememberResourceKey(fileID string, urlStr string) {
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return
+	}
+	key := parsedURL.Query().Get("resourcekey")
+	if key == "" {
+		return
+	}
+
+	g.resourceKeysMu.Lock()
+	defer g.resourceKeysMu.Unlock()
+	if g.resourceKeys == nil {
+		g.resourceKeys = make(map[string]string)
+	}
+	g.resourceKeys[fileID] = key
+}
+
+// withResourceKey adds the resource key of fileID, if known, to the query
+// of a uc, usercontent or export URL.
+func (g *Client) withResourceKey(urlStr string, fileID string) string {
+	g.resourceKeysMu.Lock()
+	key := g.resourceKeys[fileID]
+	g.resourceKeysMu.Unlock()
+	if key == "" {
+		return urlStr
+	}
+
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil {
+		return urlStr
+	}
+	query := parsedURL.Query()
+	query.Set("resourcekey", key)
+	parsedURL.RawQuery = query.Encode()
+	return parsedURL.String()
+}
+
+// resourceKeysHeader is the X-Goog-Drive-Resource-Keys value the Drive API
+// takes for every known resource key: "id/key" pairs separated by commas.
+func (g *Client) resourceKeysHeader() string {
+	g.resourceKeysMu.Lock()
+	defer g.resourceKeysMu.Unlock()
+
+	var pairs []string
+	for id, key := range g.resourceKeys {
+		pairs = append(pairs, id+"/"+key)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,147 @@
+package gget
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	MAX_RETRY_WAIT  = time.Minute
+	MAX_RETRY_AFTER = 10 * time.Minute
+)
+
+// readError marks a failure while reading a response body. Unlike local
+// write failures these are worth retrying from the bytes already written.
+type readError struct {
+	err error
+}
+
+func (e *readError) Error() string {
+	return fmt.Sprintf("download error: %v", e.err)
+}
+
+func (e *readError) Unwrap() error {
+	return e.err
+}
+
+func isReadError(err error) bool {
+	var re *readError
+	return errors.As(err, &re)
+}
+
+// retryable reports whether a response is worth retrying: a server error
+// or a request to slow down.
+func retryable(resp *http.Response) bool {
+	return resp.StatusCode >= 500 || rateLimited(resp)
+}
+
+// rateLimited reports whether resp is a 429, or a 403 whose Drive API
+// error reason is a rate limit rather than a lack of access. The body of a
+// 403 is peeked at and left readable.
+func rateLimited(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+		return bytes.Contains(data, []byte(`"rateLimitExceeded"`)) || bytes.Contains(data, []byte(`"userRateLimitExceeded"`))
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header of resp, given either in
+// seconds or as an HTTP date. It returns zero when there is none.
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+// backoff returns the delay before retry attempt (starting at 1): the
+// -retry-wait base doubled per attempt, plus up to 50% jitter, capped at
+// MAX_RETRY_WAIT.
+func (g *Client) backoff(attempt int) time.Duration {
+	wait := g.RetryWait << (attempt - 1)
+	if wait <= 0 || wait > MAX_RETRY_WAIT {
+		wait = MAX_RETRY_WAIT
+	}
+	return wait + time.Duration(rand.Int63n(int64(wait)/2+1))
+}
+
+// sleepBeforeRetry announces and waits out the backoff for attempt,
+// returning early if ctx is cancelled.
+func (g *Client) sleepBeforeRetry(ctx context.Context, attempt int, reason interface{}) error {
+	return g.sleepFor(ctx, g.backoff(attempt), attempt, reason)
+}
+
+// sleepFor is sleepBeforeRetry with the delay chosen by the caller.
+func (g *Client) sleepFor(ctx context.Context, wait time.Duration, attempt int, reason interface{}) error {
+	g.stats.retry()
+	if !g.Quiet {
+		fmt.Fprintf(g.Log, "\n%v; retrying in %v (attempt %d of %d)\n", reason, wait.Round(100*time.Millisecond), attempt, g.Retries)
+	}
+	return sleep(ctx, wait)
+}
+
+// do sends req, retrying network errors, rate limits and 5xx responses
+// with exponential backoff, or after the delay the server asks for in
+// Retry-After (capped at MAX_RETRY_AFTER, plus jitter). The final failing
+// response is returned as-is.
+func (g *Client) do(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		started := time.Now()
+		resp, err := g.client.Do(g.traceRequest(req))
+		g.debugResult(resp, err, started)
+		if err == nil && !retryable(resp) {
+			resp.Body = g.watchBody(resp.Body)
+			return resp, nil
+		}
+		if req.Context().Err() != nil {
+			return resp, err
+		}
+		if attempt > g.Retries {
+			if err != nil {
+				return nil, classify(ErrNetwork, err)
+			}
+			return resp, nil
+		}
+
+		var reason interface{} = err
+		wait := g.backoff(attempt)
+		if err == nil {
+			reason = resp.Status
+			if after := min(retryAfter(resp), MAX_RETRY_AFTER); after > 0 {
+				wait = after + time.Duration(rand.Int63n(int64(after)/10+1))
+			}
+			resp.Body.Close()
+		}
+		if err := g.sleepFor(req.Context(), wait, attempt, reason); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			// The previous attempt consumed the body
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
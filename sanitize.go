@@ -0,0 +1,137 @@
+package gget
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// FilenameRestriction selects how strictly remote file names are cleaned
+// before they are used as local paths.
+type FilenameRestriction int
+
+const (
+	// RESTRICT_NONE only removes what is unsafe on the current system:
+	// path separators, control characters and "." or "..".
+	RESTRICT_NONE FilenameRestriction = iota
+	// RESTRICT_WINDOWS also applies Windows rules on every system, for
+	// files that will be copied to Windows later.
+	RESTRICT_WINDOWS
+	// RESTRICT_ASCII additionally replaces spaces and non-ASCII characters.
+	RESTRICT_ASCII
+)
+
+const MAX_FILENAME_LENGTH = 255
+
+// ParseFilenameRestriction parses a -restrict-filenames level: none,
+// windows or ascii.
+func ParseFilenameRestriction(level string) (FilenameRestriction, error) {
+	switch strings.ToLower(level) {
+	case "", "none":
+		return RESTRICT_NONE, nil
+	case "windows":
+		return RESTRICT_WINDOWS, nil
+	case "ascii":
+		return RESTRICT_ASCII, nil
+	}
+	return 0, fmt.Errorf("unknown filename restriction %q (use none, windows or ascii)", level)
+}
+
+// FilenameNormalization selects the Unicode normalization form of local
+// file names. Drive keeps names as they were uploaded, so a name created
+// on macOS may arrive decomposed (NFD) and look like a duplicate of the
+// composed (NFC) name that Linux and Windows tools produce.
+type FilenameNormalization int
+
+const (
+	NORMALIZE_NONE FilenameNormalization = iota // keep names as Drive reports them
+	NORMALIZE_NFC                               // composed, as most Linux and Windows software writes
+	NORMALIZE_NFD                               // decomposed, as HFS+ on macOS stored names
+)
+
+// ParseFilenameNormalization parses a -normalize-filenames form: nfc,
+// nfd or none.
+func ParseFilenameNormalization(form string) (FilenameNormalization, error) {
+	switch strings.ToLower(form) {
+	case "", "none":
+		return NORMALIZE_NONE, nil
+	case "nfc":
+		return NORMALIZE_NFC, nil
+	case "nfd":
+		return NORMALIZE_NFD, nil
+	}
+	return 0, fmt.Errorf("unknown filename normalization %q (use nfc, nfd or none)", form)
+}
+
+// normalizeName applies the NormalizeNames form to name.
+func (g *Client) normalizeName(name string) string {
+	switch g.NormalizeNames {
+	case NORMALIZE_NFC:
+		return norm.NFC.String(name)
+	case NORMALIZE_NFD:
+		return norm.NFD.String(name)
+	}
+	return name
+}
+
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+	"COM¹": true, "COM²": true, "COM³": true, "LPT¹": true, "LPT²": true, "LPT³": true,
+	"CONIN$": true, "CONOUT$": true, "CLOCK$": true,
+}
+
+// sanitizeName turns a name reported by the server into a single safe
+// path component, so that it can neither escape the output directory nor
+// be rejected by the file system.
+func (g *Client) sanitizeName(name string) string {
+	windows := runtime.GOOS == "windows" || g.RestrictFilenames >= RESTRICT_WINDOWS
+	name = g.normalizeName(name)
+
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r == '/' || r == '\\' || r < 0x20 || r == 0x7f || r == utf8.RuneError:
+			r = '_'
+		case windows && strings.ContainsRune(`<>:"|?*`, r):
+			r = '_'
+		case g.RestrictFilenames >= RESTRICT_ASCII && (r == ' ' || r > 0x7e):
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	name = b.String()
+
+	if windows {
+		name = strings.TrimRight(name, ". ")
+		stem, _, _ := strings.Cut(name, ".")
+		if windowsReserved[strings.ToUpper(strings.TrimSpace(stem))] {
+			name = "_" + name
+		}
+	}
+
+	switch name {
+	case "":
+		return "download"
+	case ".", "..":
+		return "_" + name
+	}
+
+	if len(name) > MAX_FILENAME_LENGTH {
+		ext := filepath.Ext(name)
+		if len(ext) > MAX_FILENAME_LENGTH/2 {
+			ext = ""
+		}
+		stem := name[:len(name)-len(ext)]
+		for len(stem)+len(ext) > MAX_FILENAME_LENGTH || !utf8.ValidString(stem) {
+			stem = stem[:len(stem)-1]
+		}
+		name = stem + ext
+	}
+	return name
+}
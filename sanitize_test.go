@@ -0,0 +1,49 @@
+package gget
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeNameWindows(t *testing.T) {
+	g := &Client{RestrictFilenames: RESTRICT_WINDOWS}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"report.pdf", "report.pdf"},
+		{"a/b\\c", "a_b_c"},
+		{`what?<>:"|*.txt`, "what_______.txt"},
+		{"trailing. ", "trailing"},
+		{"..", "download"},
+		{"", "download"},
+		{"CON", "_CON"},
+		{"nul.txt", "_nul.txt"},
+		{"COM1.log", "_COM1.log"},
+		{"LPT9", "_LPT9"},
+		{"COM¹", "_COM¹"},
+		{"com².txt", "_com².txt"},
+		{"COM³", "_COM³"},
+		{"LPT¹.txt", "_LPT¹.txt"},
+		{"lpt²", "_lpt²"},
+		{"LPT³", "_LPT³"},
+		{"CONIN$", "_CONIN$"},
+		{"conout$.txt", "_conout$.txt"},
+		{"CLOCK$", "_CLOCK$"},
+		{"CONSOLE", "CONSOLE"},
+		{"COM10", "COM10"},
+	}
+	for _, tt := range tests {
+		if got := g.sanitizeName(tt.name); got != tt.want {
+			t.Errorf("sanitizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeNameLength(t *testing.T) {
+	g := &Client{}
+	got := g.sanitizeName(strings.Repeat("é", MAX_FILENAME_LENGTH) + ".txt")
+	if len(got) > MAX_FILENAME_LENGTH || !strings.HasSuffix(got, "é.txt") {
+		t.Errorf("sanitizeName of a long name = %q (%d bytes)", got, len(got))
+	}
+}
@@ -0,0 +1,68 @@
+package gget
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Search runs a Drive API query, such as "name contains 'report' and
+// mimeType = 'application/pdf'", over the files the login can see and
+// returns the matches, most recently modified first. Trashed files are
+// left out unless the query mentions trashed or IncludeTrashed is set. See
+// https://developers.google.com/drive/api/guides/search-files for the
+// query syntax.
+func (g *Client) Search(ctx context.Context, query string) ([]*FileInfo, error) {
+	if g.Auth == nil {
+		return nil, classify(ErrPermissionDenied, errNeedsAuth)
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("empty search query")
+	}
+	if !strings.Contains(query, "trashed") && !g.IncludeTrashed {
+		query = "(" + query + ") and trashed = false"
+	}
+
+	files, err := g.queryFiles(ctx, query, "modifiedTime desc")
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*FileInfo, len(files))
+	for i := range files {
+		items[i] = files[i].info()
+	}
+	return items, nil
+}
+
+// queryFiles pages through the Drive API files matching q, in orderBy
+// order.
+func (g *Client) queryFiles(ctx context.Context, q string, orderBy string) ([]driveFile, error) {
+	var files []driveFile
+	pageToken := ""
+	for {
+		query := allDrives(url.Values{
+			"q":        {q},
+			"fields":   {"nextPageToken,files(" + driveFileFields + ")"},
+			"pageSize": {"1000"},
+			"orderBy":  {orderBy},
+		})
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		var page struct {
+			NextPageToken string      `json:"nextPageToken"`
+			Files         []driveFile `json:"files"`
+		}
+		if err := g.getJSON(ctx, apiURL("/files", query), &page); err != nil {
+			return nil, err
+		}
+		files = append(files, page.Files...)
+
+		if page.NextPageToken == "" {
+			return files, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
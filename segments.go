@@ -0,0 +1,155 @@
+package gget
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// downloadSegmented splits a download of size bytes into -chunks byte
+// ranges that are fetched concurrently into a preallocated .part file.
+// Passing the segments of an interrupted run resumes each of them.
+func (g *Client) downloadSegmented(ctx context.Context, urlStr string, t *transfer, size int64, segments []*segment) error {
+	flags := os.O_CREATE | os.O_WRONLY
+	if segments == nil {
+		flags |= os.O_TRUNC
+	}
+	out, err := os.OpenFile(t.path()+".part", flags, 0666)
+	if err != nil {
+		return classify(ErrDisk, fmt.Errorf("failed to create output file: %v", err))
+	}
+	defer out.Close()
+
+	if err := out.Truncate(size); err != nil {
+		return classify(ErrDisk, fmt.Errorf("failed to preallocate output file: %v", err))
+	}
+
+	if segments == nil {
+		segmentSize := (size + int64(g.Chunks) - 1) / int64(g.Chunks)
+		for start := int64(0); start < size; start += segmentSize {
+			segments = append(segments, &segment{Start: start, End: min(start+segmentSize, size) - 1, Pos: start})
+		}
+	}
+
+	var written atomic.Int64
+	for _, seg := range segments {
+		written.Add(seg.Pos - seg.Start)
+	}
+	// The .part file is preallocated, so if the process dies without
+	// reaching interrupted only this state says which bytes are valid
+	saveState(t, partState{URL: urlStr, Size: size, Written: written.Load(), Segments: segments})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(segments))
+
+	for _, seg := range segments {
+		wg.Add(1)
+		go func(seg *segment) {
+			defer wg.Done()
+			errs <- g.fetchSegment(ctx, urlStr, out, seg, &written)
+		}(seg)
+	}
+
+	bar := g.newProgress(t, size, written.Load())
+	defer bar.close()
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				bar.update(written.Load())
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(done)
+	close(errs)
+
+	if ctx.Err() != nil {
+		return g.interrupted(ctx, t, out, partState{URL: urlStr, Size: size, Written: written.Load(), Segments: segments})
+	}
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	bar.finish(written.Load())
+
+	out.Close()
+	return g.finalizeOutput(t)
+}
+
+// fetchSegment writes the remaining bytes of seg at their offsets in out,
+// re-requesting the remainder if the connection ends early.
+func (g *Client) fetchSegment(ctx context.Context, urlStr string, out *os.File, seg *segment, written *atomic.Int64) error {
+	buffer := make([]byte, CHUNK_SIZE)
+
+	var readErr error
+	for attempt := 0; seg.Pos <= seg.End; attempt++ {
+		if attempt > g.Retries {
+			if readErr != nil {
+				return classify(ErrNetwork, &readError{readErr})
+			}
+			return classify(ErrNetwork, fmt.Errorf("incomplete segment %d-%d: stopped at byte %d", seg.Start, seg.End, seg.Pos))
+		}
+		if readErr != nil {
+			if err := g.sleepBeforeRetry(ctx, attempt, readErr); err != nil {
+				return err
+			}
+			readErr = nil
+		}
+
+		resp, err := g.requestRange(ctx, urlStr, seg.Pos, seg.End)
+		if err != nil {
+			return fmt.Errorf("segment request failed: %w", err)
+		}
+
+		for seg.Pos <= seg.End {
+			if err := g.waitPaused(ctx); err != nil {
+				resp.Body.Close()
+				return err
+			}
+			n, err := resp.Body.Read(buffer)
+			if n > 0 {
+				if g.limiter != nil {
+					if err := g.limiter.wait(ctx, n); err != nil {
+						resp.Body.Close()
+						return err
+					}
+				}
+				n = int(min(int64(n), seg.End-seg.Pos+1))
+				if _, writeErr := out.WriteAt(buffer[:n], seg.Pos); writeErr != nil {
+					resp.Body.Close()
+					return classify(ErrDisk, fmt.Errorf("failed to write to file: %v", writeErr))
+				}
+				seg.Pos += int64(n)
+				written.Add(int64(n))
+				g.stats.addBytes(n)
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					resp.Body.Close()
+					return ctx.Err()
+				}
+				readErr = err
+				break
+			}
+		}
+		resp.Body.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package gget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// partState is recorded next to the .part file of an interrupted download
+// so that a later run with Resume knows how much of it is valid.
+type partState struct {
+	URL      string     `json:"url"`
+	Size     int64      `json:"size"`
+	Written  int64      `json:"written"`
+	MD5      string     `json:"md5,omitempty"`
+	Segments []*segment `json:"segments,omitempty"`
+	Updated  time.Time  `json:"updated"`
+}
+
+// segment is one byte range of a segmented download; Pos is the next
+// byte still to be fetched.
+type segment struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	Pos   int64 `json:"pos"`
+}
+
+func statePath(output string) string {
+	return output + ".part.json"
+}
+
+// loadState returns the saved state for output, or nil if there is none.
+func loadState(output string) *partState {
+	data, err := os.ReadFile(statePath(output))
+	if err != nil {
+		return nil
+	}
+	var state partState
+	if json.Unmarshal(data, &state) != nil {
+		return nil
+	}
+	return &state
+}
+
+// saveState writes the state file for the .part file of t.
+func saveState(t *transfer, state partState) {
+	state.MD5 = t.md5
+	state.Updated = time.Now()
+	if data, err := json.MarshalIndent(state, "", "  "); err == nil {
+		os.WriteFile(statePath(t.path()), data, 0644)
+	}
+}
+
+// interrupted flushes the partial file, records its state and returns an
+// error wrapping the context's cancellation cause.
+func (g *Client) interrupted(ctx context.Context, t *transfer, out *os.File, state partState) error {
+	out.Sync()
+	saveState(t, state)
+	return fmt.Errorf("%w: partial download kept in %s", ctx.Err(), t.output+".part")
+}
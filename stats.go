@@ -0,0 +1,84 @@
+package gget
+
+import (
+	"sync"
+	"time"
+)
+
+// Stats totals the activity of a Client since it was created, for the
+// report printed at the end of a run.
+type Stats struct {
+	Files     int   `json:"files"`      // finished downloads
+	Skipped   int   `json:"skipped"`    // files left alone because they were already complete
+	Bytes     int64 `json:"bytes"`      // bytes received, across all files
+	Retries   int   `json:"retries"`    // requests and transfers retried after a failure
+	PeakSpeed int64 `json:"peak_speed"` // highest bytes per second over one second
+}
+
+// runStats collects Stats as transfers go. Peak speed is measured over
+// the bytes of all concurrent transfers together.
+type runStats struct {
+	mu          sync.Mutex
+	stats       Stats
+	windowStart time.Time
+	windowBytes int64
+}
+
+func (s *runStats) addBytes(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Bytes += int64(n)
+
+	now := time.Now()
+	if s.windowStart.IsZero() {
+		s.windowStart = now
+	}
+	s.windowBytes += int64(n)
+	if elapsed := now.Sub(s.windowStart); elapsed >= time.Second {
+		s.stats.PeakSpeed = max(s.stats.PeakSpeed, int64(float64(s.windowBytes)/elapsed.Seconds()))
+		s.windowStart, s.windowBytes = now, 0
+	}
+}
+
+// count records an event of the given type.
+func (s *runStats) count(eventType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch eventType {
+	case "done":
+		s.stats.Files++
+	case "skip":
+		s.stats.Skipped++
+	}
+}
+
+func (s *runStats) retry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Retries++
+}
+
+// Stats returns the totals of everything the client has done so far.
+// Runs shorter than a second have no full window to measure a peak over,
+// so their average speed stands in for it.
+func (g *Client) Stats() Stats {
+	g.stats.mu.Lock()
+	defer g.stats.mu.Unlock()
+	stats := g.stats.stats
+	if stats.PeakSpeed == 0 && !g.stats.windowStart.IsZero() {
+		if elapsed := time.Since(g.stats.windowStart).Seconds(); elapsed > 0 {
+			stats.PeakSpeed = int64(float64(g.stats.windowBytes) / elapsed)
+		}
+	}
+	return stats
+}
+
+// EmitSummary writes stats to Events as a summary event, with the
+// average speed over elapsed, the wall time of the run.
+func (g *Client) EmitSummary(stats Stats, elapsed time.Duration) {
+	event := Event{Type: "summary", Bytes: stats.Bytes, Duration: elapsed.Seconds(), Stats: &stats}
+	if elapsed > 0 {
+		event.Speed = int64(float64(stats.Bytes) / elapsed.Seconds())
+	}
+	g.emit(event)
+}
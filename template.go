@@ -0,0 +1,60 @@
+package gget
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// templateField matches a {variable} in an output template.
+var templateField = regexp.MustCompile(`\{([a-z]*)\}`)
+
+var templateFields = map[string]bool{"name": true, "id": true, "ext": true, "date": true, "index": true}
+
+// SetOutputTemplate names downloads saved under their remote names after
+// tmpl instead, replacing {name} with the remote name, {id} with the Drive
+// file ID, {ext} with the extension (without the dot), {date} with the
+// download date (YYYY-MM-DD) and {index} with the file's position in its
+// folder or in the batch (Options.Index), starting at 1. A "/" in tmpl
+// creates subdirectories; an empty tmpl keeps the remote names.
+func (g *Client) SetOutputTemplate(tmpl string) error {
+	for _, match := range templateField.FindAllStringSubmatch(tmpl, -1) {
+		if !templateFields[match[1]] {
+			return fmt.Errorf("unknown output template variable %s", match[0])
+		}
+	}
+	g.outputTemplate = tmpl
+	return nil
+}
+
+// applyTemplate names the file called name (already sanitized) after the
+// output template, if one is set.
+func (g *Client) applyTemplate(name string, id string, index int) string {
+	if g.outputTemplate == "" {
+		return name
+	}
+	index = max(index, 1)
+	values := map[string]string{
+		"name":  name,
+		"id":    g.sanitizeName(id),
+		"ext":   strings.TrimPrefix(filepath.Ext(name), "."),
+		"date":  time.Now().Format(time.DateOnly),
+		"index": strconv.Itoa(index),
+	}
+	expanded := templateField.ReplaceAllStringFunc(g.outputTemplate, func(field string) string {
+		return values[strings.Trim(field, "{}")]
+	})
+	return filepath.FromSlash(expanded)
+}
+
+// batchIndex returns the Options.Index of the Download call made with ctx.
+func batchIndex(ctx context.Context) int {
+	if opts, ok := ctx.Value(optionsKey{}).(*Options); ok {
+		return opts.Index
+	}
+	return 0
+}
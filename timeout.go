@@ -0,0 +1,114 @@
+package gget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	CONNECT_TIMEOUT = 30 * time.Second
+	READ_TIMEOUT    = time.Minute
+	MIN_SPEED_TIME  = 30 * time.Second
+)
+
+// SetConnectTimeout limits how long opening a connection, including the
+// TLS handshake, may take; zero removes the limit.
+func (g *Client) SetConnectTimeout(timeout time.Duration) {
+	g.dialer.Timeout = timeout
+	g.Transport.TLSHandshakeTimeout = timeout
+}
+
+// withMaxTime bounds ctx by MaxTime, if set.
+func (g *Client) withMaxTime(ctx context.Context) (context.Context, context.CancelFunc) {
+	if g.MaxTime <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, g.MaxTime)
+}
+
+// maxTimeError explains an error caused by MaxTime running out, as opposed
+// to the caller's own deadline.
+func (g *Client) maxTimeError(parent context.Context, err error) error {
+	if err == nil || parent.Err() != nil || !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return classify(ErrNetwork, fmt.Errorf("download took longer than %v: %w", g.MaxTime, err))
+}
+
+// watchedBody closes a response body whose reads have waited longer than
+// timeout without receiving any data, or have averaged less than minSpeed
+// bytes per second over minSpeedTime, so that a hung or crawling transfer
+// fails with a read error and is resumed from its current offset like any
+// other. Time spent outside Read, such as in the rate limiter, does not
+// count.
+type watchedBody struct {
+	body    io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	stalled atomic.Bool
+
+	minSpeed     int64
+	minSpeedTime time.Duration
+	window       time.Duration // time spent reading since the last speed check
+	windowBytes  int64
+}
+
+func (r *watchedBody) Read(p []byte) (int, error) {
+	if r.timer != nil {
+		r.timer.Reset(r.timeout)
+	}
+	start := time.Now()
+	n, err := r.body.Read(p)
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	if err != nil && r.stalled.Load() {
+		return n, fmt.Errorf("no data received for %v", r.timeout)
+	}
+
+	if r.minSpeed > 0 && err == nil {
+		r.window += time.Since(start)
+		r.windowBytes += int64(n)
+		if r.window >= r.minSpeedTime {
+			if float64(r.windowBytes)/r.window.Seconds() < float64(r.minSpeed) {
+				r.body.Close()
+				return n, fmt.Errorf("transfer slower than %s/s for %v", FormatSize(r.minSpeed), r.minSpeedTime)
+			}
+			r.window, r.windowBytes = 0, 0
+		}
+	}
+	return n, err
+}
+
+func (r *watchedBody) Close() error {
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	return r.body.Close()
+}
+
+// watchBody applies the ReadTimeout and MinSpeed watchdogs to a response
+// body.
+func (g *Client) watchBody(body io.ReadCloser) io.ReadCloser {
+	if (g.ReadTimeout <= 0 && g.MinSpeed <= 0) || body == nil || body == http.NoBody {
+		return body
+	}
+
+	r := &watchedBody{body: body, timeout: g.ReadTimeout, minSpeed: g.MinSpeed, minSpeedTime: g.MinSpeedTime}
+	if r.minSpeedTime <= 0 {
+		r.minSpeedTime = MIN_SPEED_TIME
+	}
+	if r.timeout > 0 {
+		r.timer = time.AfterFunc(r.timeout, func() {
+			r.stalled.Store(true)
+			body.Close()
+		})
+		r.timer.Stop()
+	}
+	return r
+}
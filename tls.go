@@ -0,0 +1,102 @@
+package gget
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// TLSConfig returns the TLS configuration of the transport, creating it if
+// needed, so that it can be adjusted in place.
+func (g *Client) TLSConfig() *tls.Config {
+	if g.Transport.TLSClientConfig == nil {
+		g.Transport.TLSClientConfig = &tls.Config{}
+	}
+	return g.Transport.TLSClientConfig
+}
+
+// SetCACertificates trusts the PEM certificates in path in addition to the
+// system roots, as needed behind TLS-intercepting proxies.
+func (g *Client) SetCACertificates(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read CA certificates: %v", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	g.TLSConfig().RootCAs = pool
+	return nil
+}
+
+// SetClientCertificate presents the PEM certificate in certFile to servers
+// that require client authentication. keyFile may be empty when certFile
+// also holds the private key.
+func (g *Client) SetClientCertificate(certFile string, keyFile string) error {
+	if keyFile == "" {
+		keyFile = certFile
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load client certificate: %v", err)
+	}
+	g.TLSConfig().Certificates = []tls.Certificate{cert}
+	return nil
+}
+
+// ParseTLSVersion parses a TLS version such as "1.2" for use as
+// tls.Config.MinVersion.
+func ParseTLSVersion(version string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(version), "tls") {
+	case "1.0", "1":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unknown TLS version %q (use 1.0, 1.1, 1.2 or 1.3)", version)
+}
+
+// SetCipherSuites restricts TLS 1.0-1.2 connections to the named cipher
+// suites, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. TLS 1.3 suites are
+// not configurable.
+func (g *Client) SetCipherSuites(names []string) error {
+	suites := make(map[string]uint16)
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		suites[suite.Name] = suite.ID
+	}
+
+	var ids []uint16
+	for _, name := range names {
+		id, ok := suites[strings.ToUpper(strings.TrimSpace(name))]
+		if !ok {
+			return fmt.Errorf("unknown cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	g.TLSConfig().CipherSuites = ids
+	return nil
+}
+
+// DisableHTTP2 makes the transport speak HTTP/1.1 only, for middleboxes
+// that break HTTP/2.
+func (g *Client) DisableHTTP2() {
+	g.Transport.ForceAttemptHTTP2 = false
+	g.Transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	// The transport was cloned with HTTP/2 already set up, so h2 is also
+	// offered during the TLS handshake
+	config := g.TLSConfig()
+	config.NextProtos = slices.DeleteFunc(slices.Clone(config.NextProtos), func(proto string) bool { return proto == "h2" })
+}
@@ -0,0 +1,129 @@
+package gget
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TokenStore selects where Login keeps the OAuth refresh token.
+type TokenStore int
+
+const (
+	// STORE_AUTO uses the system keyring when one is available and
+	// token.json in the config directory otherwise.
+	STORE_AUTO TokenStore = iota
+	// STORE_FILE always uses token.json, readable only by the user.
+	STORE_FILE
+	// STORE_KEYRING always uses the system keyring: the macOS Keychain,
+	// the Windows Credential Manager or the Secret Service (through
+	// secret-tool) elsewhere.
+	STORE_KEYRING
+)
+
+const (
+	KEYRING_SERVICE = "gget"
+	KEYRING_ACCOUNT = "oauth-token"
+)
+
+// TokenStorage is where Login saves the token and where UseSavedLogin
+// and Logout look for it.
+var TokenStorage = STORE_AUTO
+
+// errNoKeyringItem reports that the keyring holds no saved token.
+var errNoKeyringItem = errors.New("no token in the keyring")
+
+// ParseTokenStore parses a -token-store value: auto, file or keyring.
+func ParseTokenStore(store string) (TokenStore, error) {
+	switch strings.ToLower(store) {
+	case "", "auto":
+		return STORE_AUTO, nil
+	case "file":
+		return STORE_FILE, nil
+	case "keyring":
+		return STORE_KEYRING, nil
+	}
+	return STORE_AUTO, fmt.Errorf("invalid token store %q (use auto, file or keyring)", store)
+}
+
+// saveToken stores data, the JSON of a savedToken, and says where. A
+// token moved into the keyring no longer stays in the file.
+func saveToken(data []byte) (string, error) {
+	if TokenStorage != STORE_FILE {
+		err := keyringSet(data)
+		if err == nil {
+			if path, err := tokenPath(); err == nil {
+				os.Remove(path)
+			}
+			return "the system keyring", nil
+		}
+		if TokenStorage == STORE_KEYRING {
+			return "", fmt.Errorf("failed to save token in the keyring: %v", err)
+		}
+	}
+
+	path, err := tokenPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to save token: %v", err)
+	}
+	return path, nil
+}
+
+// loadToken returns the saved token's JSON and where it came from, or nil
+// data if there is none. With STORE_AUTO a keyring that cannot be reached
+// counts as empty.
+func loadToken() ([]byte, string, error) {
+	if TokenStorage != STORE_FILE {
+		data, err := keyringGet()
+		switch {
+		case err == nil:
+			return data, "the system keyring", nil
+		case TokenStorage == STORE_KEYRING && errors.Is(err, errNoKeyringItem):
+			return nil, "", nil
+		case TokenStorage == STORE_KEYRING:
+			return nil, "", fmt.Errorf("failed to read token from the keyring: %v", err)
+		}
+	}
+
+	path, err := tokenPath()
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read cached token: %v", err)
+	}
+	return data, path, nil
+}
+
+// deleteToken removes the saved token from the stores TokenStorage uses.
+func deleteToken() error {
+	if TokenStorage != STORE_FILE {
+		if err := keyringDelete(); err != nil && !errors.Is(err, errNoKeyringItem) && TokenStorage == STORE_KEYRING {
+			return fmt.Errorf("failed to remove token from the keyring: %v", err)
+		}
+	}
+	if TokenStorage == STORE_KEYRING {
+		return nil
+	}
+
+	path, err := tokenPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove cached token: %v", err)
+	}
+	return nil
+}
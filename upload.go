@@ -0,0 +1,278 @@
+package gget
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	DRIVE_UPLOAD_URL = "https://www.googleapis.com/upload/drive/v3"
+	// UPLOAD_CHUNK_SIZE must be a multiple of 256 KiB
+	UPLOAD_CHUNK_SIZE = 8 * 1024 * 1024
+)
+
+// Upload uploads the file at path into the Drive folder parentID (the
+// root of My Drive when empty) with the resumable upload protocol, so that
+// failed chunks are retried from the last byte Drive received. It needs
+// a login or service account with DRIVE_WRITE_SCOPE.
+func (g *Client) Upload(ctx context.Context, path string, parentID string) (*FileInfo, error) {
+	if g.Auth == nil {
+		return nil, classify(ErrPermissionDenied, errNeedsAuth)
+	}
+	return g.upload(ctx, path, parentID, "")
+}
+
+// upload sends the file at path to a new file in parentID, or as the new
+// content of the existing file fileID when that is set.
+func (g *Client) upload(ctx context.Context, path string, parentID string, fileID string) (*FileInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, classify(ErrDisk, fmt.Errorf("failed to open %s: %v", path, err))
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, classify(ErrDisk, fmt.Errorf("failed to stat %s: %v", path, err))
+	}
+	if !stat.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+	size := stat.Size()
+
+	name := filepath.Base(path)
+	session, err := g.startUpload(ctx, name, parentID, fileID, size)
+	if err != nil {
+		return nil, err
+	}
+
+	bar := g.newProgress(&transfer{output: name}, size, 0)
+	bar.verb = "Uploading"
+	defer bar.close()
+
+	var offset int64
+	query := false
+	for attempt := 0; ; {
+		var resp *http.Response
+		queried := query
+		if query {
+			// Ask how much of the upload Drive kept
+			resp, err = g.sendUpload(ctx, session, nil, 0, fmt.Sprintf("bytes */%d", size))
+		} else {
+			end := min(offset+UPLOAD_CHUNK_SIZE, size)
+			body := &uploadReader{ctx: ctx, g: g, r: io.NewSectionReader(file, offset, end-offset), pos: offset, bar: bar}
+			resp, err = g.sendUpload(ctx, session, body, end-offset, contentRange(offset, end, size))
+		}
+		query = false
+
+		if err == nil && (resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated) {
+			defer resp.Body.Close()
+			var meta driveFile
+			if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+				return nil, fmt.Errorf("invalid API response: %v", err)
+			}
+			bar.finish(size)
+			if err := g.verifyUpload(path, &meta); err != nil {
+				return nil, err
+			}
+			return meta.info(), nil
+		}
+		if err == nil && resp.StatusCode == http.StatusPermanentRedirect {
+			// 308 Resume Incomplete: continue after the bytes Drive has
+			received := uploadedBytes(resp)
+			resp.Body.Close()
+			if received > offset {
+				attempt = 0
+			} else if !queried {
+				if attempt++; attempt > g.Retries {
+					return nil, classify(ErrNetwork, fmt.Errorf("upload stopped making progress at byte %d", offset))
+				}
+			}
+			offset = received
+			bar.update(offset)
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err == nil && !retryable(resp) {
+			defer resp.Body.Close()
+			return nil, writeError(resp)
+		}
+		attempt++
+		if attempt > g.Retries {
+			if err != nil {
+				return nil, classify(ErrNetwork, fmt.Errorf("upload failed: %v", err))
+			}
+			defer resp.Body.Close()
+			return nil, writeError(resp)
+		}
+
+		var reason interface{} = err
+		if err == nil {
+			reason = resp.Status
+			resp.Body.Close()
+		}
+		if err := g.sleepBeforeRetry(ctx, attempt, reason); err != nil {
+			return nil, err
+		}
+		query = true
+	}
+}
+
+// startUpload opens a resumable upload session and returns its URL. With
+// a fileID it replaces that file's content instead of creating a file.
+func (g *Client) startUpload(ctx context.Context, name string, parentID string, fileID string, size int64) (string, error) {
+	method, endpoint := "POST", DRIVE_UPLOAD_URL+"/files"
+	metadata := map[string]interface{}{}
+	if fileID != "" {
+		method, endpoint = "PATCH", DRIVE_UPLOAD_URL+"/files/"+url.PathEscape(fileID)
+	} else {
+		metadata["name"] = name
+		if parentID != "" {
+			metadata["parents"] = []string{parentID}
+		}
+	}
+	query := allDrives(url.Values{"uploadType": {"resumable"}, "fields": {driveFileFields}})
+	data, _ := json.Marshal(metadata)
+
+	req, err := g.newAPIRequest(ctx, method, endpoint+"?"+query.Encode(), bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Upload-Content-Length", strconv.FormatInt(size, 10))
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("X-Upload-Content-Type", contentType)
+
+	resp, err := g.do(req)
+	if err != nil {
+		return "", fmt.Errorf("upload request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", writeError(resp)
+	}
+	session := resp.Header.Get("Location")
+	if session == "" {
+		return "", fmt.Errorf("drive did not return an upload session")
+	}
+	return session, nil
+}
+
+// sendUpload PUTs body (length bytes) to the upload session with the given
+// Content-Range. Unlike do, it does not retry, since the caller resumes
+// from the offset Drive reports.
+func (g *Client) sendUpload(ctx context.Context, session string, body io.Reader, length int64, rangeHeader string) (*http.Response, error) {
+	req, err := g.newAPIRequest(ctx, "PUT", session, body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = length
+	req.Header.Set("Content-Range", rangeHeader)
+
+	return g.client.Do(req)
+}
+
+// newAPIRequest builds an authorized Drive API request with a body.
+func (g *Client) newAPIRequest(ctx context.Context, method string, urlStr string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range g.Headers {
+		req.Header.Set(key, value)
+	}
+	token, err := g.Auth.Token()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req, nil
+}
+
+// contentRange is the Content-Range of the bytes from start up to end.
+func contentRange(start, end, size int64) string {
+	if size == 0 {
+		return "bytes */0"
+	}
+	return fmt.Sprintf("bytes %d-%d/%d", start, end-1, size)
+}
+
+// uploadedBytes reads how many bytes Drive holds from the Range header of
+// a 308 response ("bytes=0-N"); none when it is missing.
+func uploadedBytes(resp *http.Response) int64 {
+	_, last, ok := strings.Cut(resp.Header.Get("Range"), "-")
+	if !ok {
+		return 0
+	}
+	end, err := strconv.ParseInt(last, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return end + 1
+}
+
+// writeError is apiError for requests that change Drive, with a hint for
+// logins without write access.
+func writeError(resp *http.Response) error {
+	err := apiError(resp)
+	if resp.StatusCode == http.StatusForbidden && strings.Contains(err.Error(), "insufficient authentication scopes") {
+		return fmt.Errorf("%w (changing Drive needs write access: log in again with gget auth login -write)", err)
+	}
+	return err
+}
+
+// verifyUpload compares the MD5 Drive computed for an uploaded file with
+// the local file.
+func (g *Client) verifyUpload(path string, meta *driveFile) error {
+	if !g.Verify || meta.MD5 == "" {
+		return nil
+	}
+	sum, err := fileMD5(path)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(sum, meta.MD5) {
+		return classify(ErrChecksumMismatch, fmt.Errorf("checksum mismatch after uploading %s: local MD5 %s, Drive has %s", path, sum, meta.MD5))
+	}
+	return nil
+}
+
+// uploadReader reads a chunk of an upload, applying the rate limit and
+// advancing the progress bar.
+type uploadReader struct {
+	ctx context.Context
+	g   *Client
+	r   io.Reader
+	pos int64
+	bar *progressBar
+}
+
+func (u *uploadReader) Read(p []byte) (int, error) {
+	n, err := u.r.Read(p)
+	if n > 0 {
+		if u.g.limiter != nil {
+			if err := u.g.limiter.wait(u.ctx, n); err != nil {
+				return 0, err
+			}
+		}
+		u.pos += int64(n)
+		u.bar.update(u.pos)
+	}
+	return n, err
+}
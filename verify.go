@@ -0,0 +1,191 @@
+package gget
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// headerMD5 extracts the MD5 checksum Google storage reports in the
+// X-Goog-Hash header ("crc32c=...,md5=<base64>"), as lowercase hex.
+func headerMD5(resp *http.Response) string {
+	for _, header := range resp.Header.Values("X-Goog-Hash") {
+		for _, part := range strings.Split(header, ",") {
+			value, ok := strings.CutPrefix(strings.TrimSpace(part), "md5=")
+			if !ok {
+				continue
+			}
+			if sum, err := base64.StdEncoding.DecodeString(value); err == nil {
+				return hex.EncodeToString(sum)
+			}
+		}
+	}
+	return ""
+}
+
+// verifyChecksum compares the MD5 of the completed .part file with the
+// remote checksum, removing the file on mismatch so it is not resumed.
+func (g *Client) verifyChecksum(t *transfer) error {
+	if !g.Verify || t.md5 == "" {
+		return nil
+	}
+
+	sum, err := fileMD5(t.path() + ".part")
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(sum, t.md5) {
+		os.Remove(t.path() + ".part")
+		os.Remove(statePath(t.path()))
+		return classify(ErrChecksumMismatch, fmt.Errorf("checksum mismatch for %s: expected MD5 %s, got %s", t.output, t.md5, sum))
+	}
+	return nil
+}
+
+// retryMismatch runs download, and while it fails with
+// ErrChecksumMismatch (which has removed the corrupt file) runs it again
+// from the start, up to g.Retries more times. Downloads streamed to stdout
+// or a writer cannot be taken back, so they are not retried.
+func (g *Client) retryMismatch(ctx context.Context, output string, download func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := download()
+		if !errors.Is(err, ErrChecksumMismatch) || attempt > g.Retries || output == "-" || ctx.Value(sinkKey{}) != nil {
+			return err
+		}
+		if err := g.sleepBeforeRetry(ctx, attempt, err); err != nil {
+			return err
+		}
+	}
+}
+
+func fileMD5(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", classify(ErrDisk, fmt.Errorf("failed to open file for verification: %v", err))
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", classify(ErrDisk, fmt.Errorf("failed to read file for verification: %v", err))
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// upToDate reports whether t.output already holds the remote file: it has
+// the remote size and, when the remote MD5 is known, the same checksum.
+func upToDate(t *transfer, size int64) bool {
+	info, err := os.Stat(t.path())
+	if err != nil || !info.Mode().IsRegular() || size < 0 || info.Size() != size {
+		return false
+	}
+	if t.md5 == "" {
+		return true
+	}
+	sum, err := fileMD5(t.path())
+	return err == nil && strings.EqualFold(sum, t.md5)
+}
+
+// FileCheck is the result of comparing one file in VerifyFolder.
+type FileCheck struct {
+	Path   string `json:"path"`   // relative to the local directory
+	Status string `json:"status"` // ok, missing, changed or extra
+	Detail string `json:"detail,omitempty"`
+}
+
+// VerifyFolder compares the local copy of a Drive folder under dir with
+// the remote folder, by size and by MD5 where the remote side reports
+// them, without downloading anything. Local files that are not in the
+// folder are reported as extra. Files excluded by SetFilters are left out.
+func (g *Client) VerifyFolder(ctx context.Context, urlStr string, dir string) ([]FileCheck, error) {
+	items, err := g.ListFolder(ctx, urlStr, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []FileCheck
+	expected := make(map[string]bool)
+	for _, item := range items {
+		if item.IsFolder() || item.MimeType == SHORTCUT_MIME_TYPE || !g.wanted(item.Path) {
+			continue
+		}
+		output, err := g.folderOutput(dir, item, len(expected)+1)
+		if err != nil {
+			return nil, err
+		}
+		rel, _ := filepath.Rel(dir, output)
+		expected[filepath.ToSlash(rel)] = true
+
+		check, err := g.checkFile(ctx, item, output)
+		if err != nil {
+			return nil, err
+		}
+		check.Path = filepath.ToSlash(rel)
+		checks = append(checks, check)
+	}
+
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		if !expected[rel] && g.wanted(rel) {
+			checks = append(checks, FileCheck{Path: rel, Status: "extra"})
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, classify(ErrDisk, fmt.Errorf("failed to read %s: %v", dir, err))
+	}
+	return checks, nil
+}
+
+// checkFile compares output with the remote item. Public listings carry
+// no sizes or checksums, so those are looked up as Info does.
+func (g *Client) checkFile(ctx context.Context, item *FileInfo, output string) (FileCheck, error) {
+	local, err := os.Stat(output)
+	if err != nil {
+		return FileCheck{Status: "missing"}, nil
+	}
+
+	size, sum := item.Size, item.MD5
+	if size < 0 && nativeKinds[item.MimeType] == "" {
+		info, err := g.Info(ctx, item.URL(), Options{})
+		if ctx.Err() != nil {
+			return FileCheck{}, ctx.Err()
+		}
+		if err == nil {
+			size, sum = info.Size, info.MD5
+		}
+	}
+
+	switch {
+	case size >= 0 && local.Size() != size:
+		return FileCheck{Status: "changed", Detail: fmt.Sprintf("size %d, remote %d", local.Size(), size)}, nil
+	case sum != "":
+		localSum, err := fileMD5(output)
+		if err != nil {
+			return FileCheck{}, err
+		}
+		if !strings.EqualFold(localSum, sum) {
+			return FileCheck{Status: "changed", Detail: fmt.Sprintf("MD5 %s, remote %s", localSum, sum)}, nil
+		}
+	case size < 0:
+		return FileCheck{Status: "ok", Detail: "remote size unknown"}, nil
+	}
+	return FileCheck{Status: "ok"}, nil
+}
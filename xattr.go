@@ -0,0 +1,69 @@
+package gget
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// writeXattrs records where t.output came from as extended attributes,
+// using the freedesktop user.xdg.origin.url name curl and wget use, plus
+// the Drive file ID and the content type when known. Filesystems without
+// extended attributes only get a message, since the download itself is
+// complete. Only Linux supports them so far.
+func (g *Client) writeXattrs(t *transfer) {
+	origin := t.url
+	if origin == "" && t.id != "" {
+		info := &FileInfo{ID: t.id}
+		if t.meta != nil {
+			info.MimeType = t.meta.MimeType
+		}
+		origin = info.URL()
+	}
+
+	attrs := [][2]string{
+		{"user.xdg.origin.url", PublicURL(origin)},
+		{"user.gget.drive_id", t.id},
+		{"user.mime_type", t.contentType},
+	}
+	for _, attr := range attrs {
+		if attr[1] == "" {
+			continue
+		}
+		if err := setXattr(t.path(), attr[0], attr[1]); err != nil {
+			fmt.Fprintf(g.Log, "Cannot set extended attributes on %s: %v\n", t.output, err)
+			return
+		}
+	}
+}
+
+// secretParams are query parameters that carry credentials, as in signed
+// S3 and Cloud Storage links or API keys.
+var secretParams = []string{
+	"access_token", "token", "key", "api_key", "apikey", "auth", "password",
+	"sig", "signature", "x-amz-signature", "x-amz-credential", "x-amz-security-token",
+	"x-goog-signature", "x-goog-credential",
+}
+
+// PublicURL returns urlStr without its user info and credential query
+// parameters, for records that others can read, such as extended
+// attributes, metadata files and manifests.
+func PublicURL(urlStr string) string {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	query := u.Query()
+	for name := range query {
+		for _, secret := range secretParams {
+			if strings.EqualFold(name, secret) {
+				query.Del(name)
+			}
+		}
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
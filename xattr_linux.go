@@ -0,0 +1,7 @@
+package gget
+
+import "syscall"
+
+func setXattr(path string, name string, value string) error {
+	return syscall.Setxattr(path, name, []byte(value), 0)
+}
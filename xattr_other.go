@@ -0,0 +1,10 @@
+//go:build !linux
+
+package gget
+
+import "errors"
+
+// setXattr is only implemented on Linux.
+func setXattr(path string, name string, value string) error {
+	return errors.ErrUnsupported
+}